@@ -0,0 +1,93 @@
+package agent
+
+import (
+	"sync"
+	"time"
+
+	"github.com/akerl/voyager/v3/travel"
+
+	"github.com/akerl/speculate/v2/creds"
+)
+
+// ttlCache is a travel.ExpiringCache which forgets credentials after a fixed lifetime
+// A zero TTL keeps credentials until they expire
+type ttlCache struct {
+	TTL     time.Duration
+	cache   *travel.MapCache
+	entries map[string]ttlEntry
+	lock    sync.Mutex
+}
+
+type ttlEntry struct {
+	hop   travel.Hop
+	added time.Time
+}
+
+func (tc *ttlCache) init() {
+	if tc.cache == nil {
+		tc.cache = &travel.MapCache{}
+		tc.entries = map[string]ttlEntry{}
+	}
+}
+
+func (tc *ttlCache) Put(h travel.Hop, c creds.Creds) error {
+	return tc.PutExpiring(h, travel.Creds{Creds: c})
+}
+
+func (tc *ttlCache) Get(h travel.Hop) (creds.Creds, bool) {
+	c, ok := tc.GetExpiring(h)
+	return c.Creds, ok
+}
+
+func (tc *ttlCache) PutExpiring(h travel.Hop, c travel.Creds) error {
+	tc.lock.Lock()
+	defer tc.lock.Unlock()
+	tc.init()
+	tc.entries[h.CacheKey()] = ttlEntry{hop: h, added: time.Now()}
+	return tc.cache.PutExpiring(h, c)
+}
+
+func (tc *ttlCache) GetExpiring(h travel.Hop) (travel.Creds, bool) {
+	tc.lock.Lock()
+	defer tc.lock.Unlock()
+	tc.init()
+	key := h.CacheKey()
+	if entry, ok := tc.entries[key]; ok && tc.TTL != 0 && time.Since(entry.added) > tc.TTL {
+		logger.InfoMsgf("agent cache entry passed timeout: %s", key)
+		delete(tc.entries, key)
+		tc.cache.Delete(h)
+		return travel.Creds{}, false
+	}
+	return tc.cache.GetExpiring(h)
+}
+
+func (tc *ttlCache) Delete(h travel.Hop) error {
+	tc.lock.Lock()
+	defer tc.lock.Unlock()
+	tc.init()
+	delete(tc.entries, h.CacheKey())
+	return tc.cache.Delete(h)
+}
+
+// Forget removes all cached credentials derived from a profile
+func (tc *ttlCache) Forget(profile string) {
+	tc.lock.Lock()
+	defer tc.lock.Unlock()
+	tc.init()
+	logger.InfoMsgf("forgetting agent cache entries for %s", profile)
+	for key, entry := range tc.entries {
+		if entry.hop.CacheProfile() == profile {
+			delete(tc.entries, key)
+			tc.cache.Delete(entry.hop)
+		}
+	}
+}
+
+// Flush removes all cached credentials
+func (tc *ttlCache) Flush() {
+	tc.lock.Lock()
+	defer tc.lock.Unlock()
+	logger.InfoMsg("flushing agent cache")
+	tc.cache = nil
+	tc.init()
+}
@@ -0,0 +1,45 @@
+package agent
+
+import (
+	"testing"
+	"time"
+
+	"github.com/akerl/voyager/v3/cartogram"
+	"github.com/akerl/voyager/v3/travel"
+
+	"github.com/akerl/speculate/v2/creds"
+)
+
+func TestTTLCacheForget(t *testing.T) {
+	tc := &ttlCache{}
+	alice := travel.Hop{Profile: "alice"}
+	bob := travel.Hop{Profile: "bob"}
+	for _, h := range []travel.Hop{alice, bob} {
+		if err := tc.PutExpiring(h, travel.Creds{Creds: creds.Creds{AccessKey: h.Profile}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tc.Forget("alice")
+	if _, ok := tc.GetExpiring(alice); ok {
+		t.Error("forgotten profile is still cached")
+	}
+	if c, ok := tc.GetExpiring(bob); !ok || c.AccessKey != "bob" {
+		t.Errorf("other profile was dropped: %+v, %t", c, ok)
+	}
+}
+
+func TestTTLCacheTimeout(t *testing.T) {
+	tc := &ttlCache{TTL: time.Millisecond}
+	h := travel.Hop{Account: cartogram.Account{Account: "123456789012"}, Role: "admin"}
+	if err := tc.PutExpiring(h, travel.Creds{Creds: creds.Creds{AccessKey: "key"}}); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(5 * time.Millisecond)
+	if _, ok := tc.GetExpiring(h); ok {
+		t.Error("entry survived past its TTL")
+	}
+	if _, ok := tc.entries[h.CacheKey()]; ok {
+		t.Error("expired entry was not removed")
+	}
+}
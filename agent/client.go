@@ -0,0 +1,198 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/akerl/voyager/v3/travel"
+)
+
+const (
+	defaultClientTimeout = 2 * time.Minute
+	timeoutEnvVar        = "VOYAGER_AGENT_TIMEOUT"
+)
+
+// Client talks to a running agent
+// It implements travel.Traverser, so it can be used as a TraverseOptions Delegate
+type Client struct {
+	SocketPath string
+	Timeout    time.Duration
+	mfaLock    sync.Mutex
+}
+
+// FromEnv returns a Client for the agent socket in the environment, if one is set
+func FromEnv() (*Client, bool) {
+	socketPath := os.Getenv(SocketEnvVar)
+	if socketPath == "" {
+		return nil, false
+	}
+	logger.InfoMsgf("found agent socket in environment: %s", socketPath)
+	c := &Client{SocketPath: socketPath}
+	if timeout := os.Getenv(timeoutEnvVar); timeout != "" {
+		d, err := time.ParseDuration(timeout)
+		if err != nil {
+			logger.InfoMsgf("ignoring invalid agent timeout: %s", err)
+		} else {
+			c.Timeout = d
+		}
+	}
+	return c, true
+}
+
+// Traverse asks the agent to traverse a path, prompting locally for MFA if needed
+func (c *Client) Traverse(p travel.Path, opts travel.TraverseOptions) (travel.Creds, error) {
+	return c.TraverseWithContext(context.Background(), p, opts)
+}
+
+// TraverseWithContext is Traverse, abandoning the request if the context is cancelled
+func (c *Client) TraverseWithContext(
+	ctx context.Context,
+	p travel.Path,
+	opts travel.TraverseOptions,
+) (travel.Creds, error) {
+	req := request{
+		Action:            actionTraverse,
+		Path:              p,
+		SessionName:       opts.SessionName,
+		Lifetime:          opts.Lifetime,
+		Strict:            opts.StrictLifetime,
+		SessionTags:       opts.SessionTags,
+		TransitiveTagKeys: opts.TransitiveTagKeys,
+		AccountTagKeys:    opts.AccountTagKeys,
+		SourceIdentity:    opts.SourceIdentity,
+		RetryAttempts:     opts.Retry.Attempts,
+		Command:           opts.Command,
+		MfaSession:        opts.MfaSession,
+		MfaCode:           opts.MfaCode,
+	}
+	resp, err := c.sendTraverse(ctx, req, opts)
+	if err != nil {
+		return travel.Creds{}, err
+	}
+	if resp.MfaNeeded != "" {
+		resp, err = c.traverseWithMfa(ctx, req, opts)
+		if err != nil {
+			return travel.Creds{}, err
+		}
+	}
+	if resp.Creds == nil {
+		return travel.Creds{}, fmt.Errorf("agent returned no credentials")
+	}
+	return resp.Creds.toCreds(), nil
+}
+
+// traverseWithMfa prompts for MFA one request at a time, retrying first in case a
+// concurrent request already gave the agent a valid MFA session
+func (c *Client) traverseWithMfa(
+	ctx context.Context,
+	req request,
+	opts travel.TraverseOptions,
+) (response, error) {
+	c.mfaLock.Lock()
+	defer c.mfaLock.Unlock()
+
+	resp, err := c.sendTraverse(ctx, req, opts)
+	if err != nil || resp.MfaNeeded == "" {
+		return resp, err
+	}
+	if req.MfaCode != "" {
+		return response{}, fmt.Errorf("agent rejected the provided mfa code")
+	}
+	if opts.MfaPrompt == nil {
+		return response{}, fmt.Errorf("agent requires mfa but no prompt is available")
+	}
+	if cp, ok := opts.MfaPrompt.(travel.ContextMfaPrompt); ok {
+		req.MfaCode, err = cp.PromptWithContext(ctx, resp.MfaNeeded)
+	} else {
+		req.MfaCode, err = opts.MfaPrompt.Prompt(resp.MfaNeeded)
+	}
+	if err != nil {
+		return response{}, err
+	}
+	resp, err = c.sendTraverse(ctx, req, opts)
+	if err == nil && resp.MfaNeeded != "" {
+		return response{}, fmt.Errorf("agent requested another mfa code for %s", resp.MfaNeeded)
+	}
+	return resp, err
+}
+
+// sendTraverse sends a traverse request and passes the retries the agent made to OnRetry
+func (c *Client) sendTraverse(
+	ctx context.Context,
+	req request,
+	opts travel.TraverseOptions,
+) (response, error) {
+	resp, err := c.send(ctx, req)
+	if opts.Retry.OnRetry != nil {
+		for _, item := range resp.Retries {
+			opts.Retry.OnRetry(item.Hop, item.Attempt, errors.New(item.Error))
+		}
+	}
+	return resp, err
+}
+
+// Lock asks the agent to drop its cache and refuse requests until unlocked
+func (c *Client) Lock(passphrase string) error {
+	_, err := c.send(context.Background(), request{Action: actionLock, Passphrase: passphrase})
+	return err
+}
+
+// Unlock re-enables a locked agent
+func (c *Client) Unlock(passphrase string) error {
+	_, err := c.send(context.Background(), request{Action: actionUnlock, Passphrase: passphrase})
+	return err
+}
+
+// Flush asks the agent to drop all cached credentials
+func (c *Client) Flush() error {
+	_, err := c.send(context.Background(), request{Action: actionFlush})
+	return err
+}
+
+// Forget asks the agent to drop cached credentials derived from a profile
+func (c *Client) Forget(profile string) error {
+	_, err := c.send(context.Background(), request{Action: actionForget, Profile: profile})
+	return err
+}
+
+func (c *Client) send(ctx context.Context, req request) (response, error) {
+	timeout := c.Timeout
+	if timeout == 0 {
+		timeout = defaultClientTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	logger.InfoMsgf("sending %s request to agent", req.Action)
+	dialer := net.Dialer{}
+	conn, err := dialer.DialContext(ctx, "unix", c.SocketPath)
+	if err != nil {
+		return response{}, fmt.Errorf("failed to connect to agent: %s", err)
+	}
+	defer conn.Close()
+	deadline, _ := ctx.Deadline()
+	if err := conn.SetDeadline(deadline); err != nil {
+		return response{}, err
+	}
+	stop := context.AfterFunc(ctx, func() {
+		conn.SetDeadline(time.Now())
+	})
+	defer stop()
+
+	if err := writeMessage(conn, req); err != nil {
+		return response{}, err
+	}
+	resp := response{}
+	if err := readMessage(conn, &resp); err != nil {
+		return response{}, err
+	}
+	if resp.Error != "" {
+		return resp, fmt.Errorf("agent error: %s", resp.Error)
+	}
+	return resp, nil
+}
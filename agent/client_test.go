@@ -0,0 +1,65 @@
+package agent
+
+import (
+	"context"
+	"net"
+	"path/filepath"
+	"testing"
+
+	"github.com/akerl/voyager/v3/travel"
+)
+
+// fakeAgent answers every request with the given response
+func fakeAgent(t *testing.T, resp response) string {
+	t.Helper()
+	socketPath := filepath.Join(t.TempDir(), socketName)
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		listener.Close()
+	})
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			req := request{}
+			if err := readMessage(conn, &req); err == nil {
+				writeMessage(conn, resp)
+			}
+			conn.Close()
+		}
+	}()
+	return socketPath
+}
+
+func TestClientReportsAgentRetries(t *testing.T) {
+	socketPath := fakeAgent(t, response{
+		Error: "throttled",
+		Retries: []retryEvent{
+			{Hop: travel.Hop{Role: "admin"}, Attempt: 1, Error: "Throttling"},
+			{Hop: travel.Hop{Role: "admin"}, Attempt: 2, Error: "Throttling"},
+		},
+	})
+	client := &Client{SocketPath: socketPath}
+
+	attempts := []int{}
+	opts := travel.DefaultTraverseOptions()
+	opts.Retry.OnRetry = func(h travel.Hop, attempt int, err error) {
+		if h.Role != "admin" || err.Error() != "Throttling" {
+			t.Errorf("unexpected retry: %+v, %s", h, err)
+		}
+		attempts = append(attempts, attempt)
+	}
+
+	path := travel.Path{{Role: "admin"}}
+	if _, err := client.TraverseWithContext(context.Background(), path, opts); err == nil {
+		t.Fatal("expected the agent error to be returned")
+	}
+	if len(attempts) != 2 || attempts[0] != 1 || attempts[1] != 2 {
+		t.Errorf("unexpected retries reported: %v", attempts)
+	}
+}
@@ -0,0 +1,53 @@
+package agent
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+)
+
+const (
+	socketName   = "agent.sock"
+	startTimeout = 5 * time.Second
+	startPoll    = 50 * time.Millisecond
+)
+
+// NewSocketPath returns a socket path inside a new private temporary directory
+func NewSocketPath() (string, error) {
+	dir, err := os.MkdirTemp("", "voyager-agent")
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, socketName), nil
+}
+
+// StartDaemon re-runs the current executable with the provided args in the background
+// and waits for it to create its socket
+func StartDaemon(socketPath string, args []string) (int, error) {
+	executable, err := os.Executable()
+	if err != nil {
+		return 0, err
+	}
+
+	logger.InfoMsgf("starting agent daemon: %s %v", executable, args)
+	cmd := exec.Command(executable, args...)
+	cmd.SysProcAttr = detachAttr()
+	if err := cmd.Start(); err != nil {
+		return 0, err
+	}
+	pid := cmd.Process.Pid
+	if err := cmd.Process.Release(); err != nil {
+		return 0, err
+	}
+
+	deadline := time.Now().Add(startTimeout)
+	for time.Now().Before(deadline) {
+		if _, err := os.Stat(socketPath); err == nil {
+			return pid, nil
+		}
+		time.Sleep(startPoll)
+	}
+	return pid, fmt.Errorf("agent did not create socket within %s", startTimeout)
+}
@@ -0,0 +1,12 @@
+//go:build windows
+
+package agent
+
+import (
+	"syscall"
+)
+
+// detachAttr is a no-op on Windows, where child processes outlive their console by default
+func detachAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{}
+}
@@ -0,0 +1,95 @@
+package agent
+
+import (
+	"encoding/json"
+	"net"
+	"time"
+
+	"github.com/akerl/voyager/v3/travel"
+
+	"github.com/akerl/speculate/v2/creds"
+	"github.com/akerl/timber/v2/log"
+)
+
+const (
+	// SocketEnvVar is the environment variable which points clients at a running agent
+	SocketEnvVar = "VOYAGER_AGENT_SOCK"
+
+	actionTraverse = "traverse"
+	actionLock     = "lock"
+	actionUnlock   = "unlock"
+	actionFlush    = "flush"
+	actionForget   = "forget"
+)
+
+var logger = log.NewLogger("voyager")
+
+type request struct {
+	Action            string            `json:"action"`
+	Path              travel.Path       `json:"path,omitempty"`
+	SessionName       string            `json:"session_name,omitempty"`
+	Lifetime          int64             `json:"lifetime,omitempty"`
+	Strict            bool              `json:"strict_lifetime,omitempty"`
+	SessionTags       map[string]string `json:"session_tags,omitempty"`
+	TransitiveTagKeys []string          `json:"transitive_tag_keys,omitempty"`
+	AccountTagKeys    []string          `json:"account_tag_keys,omitempty"`
+	SourceIdentity    string            `json:"source_identity,omitempty"`
+	RetryAttempts     int               `json:"retry_attempts,omitempty"`
+	Command           string            `json:"command,omitempty"`
+	MfaSession        bool              `json:"mfa_session,omitempty"`
+	MfaCode           string            `json:"mfa_code,omitempty"`
+	Passphrase        string            `json:"passphrase,omitempty"`
+	Profile           string            `json:"profile,omitempty"`
+}
+
+type response struct {
+	Error     string        `json:"error,omitempty"`
+	MfaNeeded string        `json:"mfa_needed,omitempty"`
+	Creds     *credsPayload `json:"creds,omitempty"`
+	Retries   []retryEvent  `json:"retries,omitempty"`
+}
+
+// retryEvent records a hop retried by the agent, so the client can pass it to its OnRetry
+type retryEvent struct {
+	Hop     travel.Hop `json:"hop"`
+	Attempt int        `json:"attempt"`
+	Error   string     `json:"error"`
+}
+
+type credsPayload struct {
+	AccessKey    string    `json:"access_key"`
+	SecretKey    string    `json:"secret_key"`
+	SessionToken string    `json:"session_token"`
+	Region       string    `json:"region"`
+	Expiration   time.Time `json:"expiration"`
+}
+
+func newCredsPayload(c travel.Creds) *credsPayload {
+	return &credsPayload{
+		AccessKey:    c.AccessKey,
+		SecretKey:    c.SecretKey,
+		SessionToken: c.SessionToken,
+		Region:       c.Region,
+		Expiration:   c.Expiration,
+	}
+}
+
+func (cp *credsPayload) toCreds() travel.Creds {
+	return travel.Creds{
+		Creds: creds.Creds{
+			AccessKey:    cp.AccessKey,
+			SecretKey:    cp.SecretKey,
+			SessionToken: cp.SessionToken,
+			Region:       cp.Region,
+		},
+		Expiration: cp.Expiration,
+	}
+}
+
+func writeMessage(conn net.Conn, msg interface{}) error {
+	return json.NewEncoder(conn).Encode(msg)
+}
+
+func readMessage(conn net.Conn, msg interface{}) error {
+	return json.NewDecoder(conn).Decode(msg)
+}
@@ -0,0 +1,212 @@
+package agent
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/akerl/voyager/v3/audit"
+	"github.com/akerl/voyager/v3/profiles"
+	"github.com/akerl/voyager/v3/travel"
+)
+
+// Server holds credentials and MFA sessions for clients connecting over a unix socket
+type Server struct {
+	SocketPath string
+	Timeout    time.Duration
+	Store      profiles.Store
+	Audit      audit.Sink
+	Hooks      []travel.Hook
+	listener   net.Listener
+	cache      *ttlCache
+	lock       sync.Mutex
+	passHash   []byte
+}
+
+type mfaNeededError struct {
+	Arn string
+}
+
+func (m mfaNeededError) Error() string {
+	return fmt.Sprintf("mfa code required for %s", m.Arn)
+}
+
+// agentMfaPrompt defers MFA prompts to the client, since the agent has no terminal
+type agentMfaPrompt struct{}
+
+func (a *agentMfaPrompt) Prompt(arn string) (string, error) {
+	return "", mfaNeededError{Arn: arn}
+}
+
+// Listen creates the socket, restricting access to the current user
+// The socket's directory must already exist, be owned by the current user, and have mode 0700,
+// as it does when created by NewSocketPath
+func (s *Server) Listen() error {
+	if s.SocketPath == "" {
+		return fmt.Errorf("socket path must be provided")
+	}
+	if s.Store == nil {
+		s.Store = &profiles.KeyringStore{}
+	}
+	s.cache = &ttlCache{TTL: s.Timeout}
+
+	if err := checkSocketDir(filepath.Dir(s.SocketPath)); err != nil {
+		return err
+	}
+
+	logger.InfoMsgf("listening on %s", s.SocketPath)
+	listener, err := listenSocket(s.SocketPath)
+	if err != nil {
+		return err
+	}
+	if err := os.Chmod(s.SocketPath, 0600); err != nil {
+		listener.Close()
+		return err
+	}
+	s.listener = listener
+	return nil
+}
+
+// Serve handles client connections until the listener is closed
+func (s *Server) Serve() error {
+	for {
+		conn, err := s.listener.Accept()
+		if errors.Is(err, net.ErrClosed) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		go s.handle(conn)
+	}
+}
+
+// Close stops the listener and removes the socket
+func (s *Server) Close() error {
+	err := s.listener.Close()
+	os.Remove(s.SocketPath)
+	return err
+}
+
+func (s *Server) handle(conn net.Conn) {
+	defer conn.Close()
+
+	req := request{}
+	if err := readMessage(conn, &req); err != nil {
+		logger.InfoMsgf("failed to read request: %s", err)
+		return
+	}
+	logger.InfoMsgf("received %s request", req.Action)
+
+	resp := s.dispatch(req)
+	if err := writeMessage(conn, resp); err != nil {
+		logger.InfoMsgf("failed to write response: %s", err)
+	}
+}
+
+func (s *Server) dispatch(req request) response {
+	switch req.Action {
+	case actionTraverse:
+		return s.traverse(req)
+	case actionLock:
+		return s.setLock(req.Passphrase)
+	case actionUnlock:
+		return s.clearLock(req.Passphrase)
+	case actionFlush:
+		s.cache.Flush()
+		return response{}
+	case actionForget:
+		if req.Profile == "" {
+			return response{Error: "no profile provided"}
+		}
+		s.cache.Forget(req.Profile)
+		return response{}
+	default:
+		return response{Error: fmt.Sprintf("unknown action: %s", req.Action)}
+	}
+}
+
+func (s *Server) traverse(req request) response {
+	if s.locked() {
+		return response{Error: "agent is locked"}
+	}
+	if len(req.Path) == 0 {
+		return response{Error: "no path provided"}
+	}
+
+	opts := travel.DefaultTraverseOptions()
+	opts.Store = s.Store
+	opts.Audit = s.Audit
+	opts.Hooks = s.Hooks
+	opts.Command = req.Command
+	opts.MfaSession = req.MfaSession
+	opts.Cache = s.cache
+	opts.MfaPrompt = &agentMfaPrompt{}
+	opts.MfaCode = req.MfaCode
+	opts.SessionName = req.SessionName
+	opts.Lifetime = req.Lifetime
+	opts.StrictLifetime = req.Strict
+	opts.SessionTags = req.SessionTags
+	opts.TransitiveTagKeys = req.TransitiveTagKeys
+	opts.AccountTagKeys = req.AccountTagKeys
+	opts.SourceIdentity = req.SourceIdentity
+	if req.RetryAttempts != 0 {
+		opts.Retry.Attempts = req.RetryAttempts
+	}
+	retries := []retryEvent{}
+	opts.Retry.OnRetry = func(h travel.Hop, attempt int, err error) {
+		retries = append(retries, retryEvent{Hop: h, Attempt: attempt, Error: err.Error()})
+	}
+
+	c, err := req.Path.TraverseWithContext(context.Background(), opts)
+	var mfaErr mfaNeededError
+	if errors.As(err, &mfaErr) {
+		return response{MfaNeeded: mfaErr.Arn, Retries: retries}
+	} else if err != nil {
+		return response{Error: err.Error(), Retries: retries}
+	}
+	return response{Creds: newCredsPayload(c), Retries: retries}
+}
+
+func (s *Server) locked() bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.passHash != nil
+}
+
+func (s *Server) setLock(passphrase string) response {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.passHash != nil {
+		return response{Error: "agent is already locked"}
+	}
+	if passphrase == "" {
+		return response{Error: "a passphrase is required to lock the agent"}
+	}
+	hash := sha256.Sum256([]byte(passphrase))
+	s.passHash = hash[:]
+	s.cache.Flush()
+	logger.InfoMsg("agent locked")
+	return response{}
+}
+
+func (s *Server) clearLock(passphrase string) response {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.passHash == nil {
+		return response{Error: "agent is not locked"}
+	}
+	hash := sha256.Sum256([]byte(passphrase))
+	if subtle.ConstantTimeCompare(hash[:], s.passHash) != 1 {
+		return response{Error: "incorrect passphrase"}
+	}
+	s.passHash = nil
+	logger.InfoMsg("agent unlocked")
+	return response{}
+}
@@ -0,0 +1,38 @@
+//go:build !windows
+
+package agent
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"syscall"
+)
+
+// checkSocketDir refuses a socket directory which other users could reach,
+// since anyone who can connect to the socket can traverse with the agent's creds
+func checkSocketDir(dir string) error {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("socket directory is not a directory: %s", dir)
+	}
+	if info.Mode().Perm() != 0700 {
+		return fmt.Errorf("socket directory must have mode 0700: %s is %#o", dir, info.Mode().Perm())
+	}
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok || int(stat.Uid) != os.Getuid() {
+		return fmt.Errorf("socket directory must be owned by the current user: %s", dir)
+	}
+	return nil
+}
+
+// listenSocket creates the socket with a restrictive umask, so it is never reachable
+// by other users before its permissions are set
+func listenSocket(socketPath string) (net.Listener, error) {
+	oldMask := syscall.Umask(0077)
+	defer syscall.Umask(oldMask)
+	return net.Listen("unix", socketPath)
+}
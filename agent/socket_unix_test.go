@@ -0,0 +1,51 @@
+//go:build !windows
+
+package agent
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestListenRefusesSharedDirectory(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Chmod(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	s := Server{SocketPath: filepath.Join(dir, socketName)}
+	if err := s.Listen(); err == nil {
+		s.Close()
+		t.Fatal("listened in a directory other users can reach")
+	}
+	info, err := os.Stat(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0755 {
+		t.Errorf("directory mode was changed to %#o", info.Mode().Perm())
+	}
+}
+
+func TestListenInPrivateDirectory(t *testing.T) {
+	socketPath, err := NewSocketPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(filepath.Dir(socketPath))
+
+	s := Server{SocketPath: socketPath}
+	if err := s.Listen(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	info, err := os.Stat(socketPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("socket has mode %#o", info.Mode().Perm())
+	}
+}
@@ -0,0 +1,25 @@
+//go:build windows
+
+package agent
+
+import (
+	"fmt"
+	"net"
+	"os"
+)
+
+// checkSocketDir only checks that the directory exists, since Windows has no mode bits to check
+func checkSocketDir(dir string) error {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("socket directory is not a directory: %s", dir)
+	}
+	return nil
+}
+
+func listenSocket(socketPath string) (net.Listener, error) {
+	return net.Listen("unix", socketPath)
+}
@@ -0,0 +1,52 @@
+package audit
+
+import (
+	"encoding/json"
+	"os"
+	"path"
+	"sync"
+
+	"github.com/akerl/voyager/v3/internal/dirs"
+)
+
+// FileSink appends events to a JSON lines file
+type FileSink struct {
+	Path string
+	lock sync.Mutex
+}
+
+// Record appends the event to the file
+func (fs *FileSink) Record(e Event) error {
+	fs.lock.Lock()
+	defer fs.lock.Unlock()
+
+	filePath, err := fs.getPath()
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	file, err := os.OpenFile(filePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+func (fs *FileSink) getPath() (string, error) {
+	if fs.Path == "" {
+		dir, err := dirs.Config()
+		if err != nil {
+			return "", err
+		}
+		fs.Path = path.Join(dir, fileName)
+		logger.InfoMsgf("set audit log path to default: %s", fs.Path)
+	}
+	return fs.Path, nil
+}
@@ -0,0 +1,81 @@
+package audit
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/akerl/timber/v2/log"
+)
+
+const (
+	fileName = "audit.log"
+
+	// EnvVar selects the audit sink: a file path, "syslog", or "off"
+	EnvVar = "VOYAGER_AUDIT_LOG"
+
+	// CacheHit indicates credentials were reused from the cache
+	CacheHit = "hit"
+	// CacheMiss indicates a new role assumption was made
+	CacheMiss = "miss"
+
+	// ResultSuccess indicates the hop succeeded
+	ResultSuccess = "success"
+	// ResultFailure indicates the hop failed
+	ResultFailure = "failure"
+)
+
+var logger = log.NewLogger("voyager")
+
+// Event records a single role assumption
+// It must never contain credentials or MFA codes
+type Event struct {
+	Time        time.Time `json:"time"`
+	Command     string    `json:"command,omitempty"`
+	Profile     string    `json:"profile"`
+	Account     string    `json:"account"`
+	Role        string    `json:"role"`
+	Mfa         bool      `json:"mfa"`
+	SessionName string    `json:"session_name,omitempty"`
+	Cache       string    `json:"cache"`
+	Result      string    `json:"result"`
+	Error       string    `json:"error,omitempty"`
+}
+
+// Sink receives audit events
+type Sink interface {
+	Record(Event) error
+}
+
+// Default returns the sink selected by the environment, or a FileSink in the config dir
+// It returns nil if auditing is disabled
+func Default() (Sink, error) {
+	setting := os.Getenv(EnvVar)
+	switch strings.ToLower(setting) {
+	case "off", "none":
+		logger.InfoMsg("audit log disabled")
+		return nil, nil
+	case "syslog":
+		return NewSyslogSink()
+	case "":
+		return &FileSink{}, nil
+	default:
+		return &FileSink{Path: setting}, nil
+	}
+}
+
+// Record sends an event to a sink, logging rather than failing on errors
+// A nil sink discards the event
+func Record(s Sink, e Event) {
+	if s == nil {
+		return
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now().UTC()
+	}
+	if err := s.Record(e); err != nil {
+		logger.InfoMsgf("failed to record audit event: %s", err)
+		fmt.Fprintf(os.Stderr, "warning: failed to write audit log: %s\n", err)
+	}
+}
@@ -0,0 +1,31 @@
+//go:build !windows
+
+package audit
+
+import (
+	"encoding/json"
+	"log/syslog"
+)
+
+// SyslogSink sends events to the local syslog daemon as JSON
+type SyslogSink struct {
+	writer *syslog.Writer
+}
+
+// NewSyslogSink connects to the local syslog daemon
+func NewSyslogSink() (*SyslogSink, error) {
+	writer, err := syslog.New(syslog.LOG_AUTH|syslog.LOG_INFO, "voyager")
+	if err != nil {
+		return nil, err
+	}
+	return &SyslogSink{writer: writer}, nil
+}
+
+// Record sends the event to syslog
+func (ss *SyslogSink) Record(e Event) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	return ss.writer.Info(string(data))
+}
@@ -0,0 +1,20 @@
+//go:build windows
+
+package audit
+
+import (
+	"fmt"
+)
+
+// SyslogSink is not supported on Windows
+type SyslogSink struct{}
+
+// NewSyslogSink returns an error, since syslog is not available on Windows
+func NewSyslogSink() (*SyslogSink, error) {
+	return nil, fmt.Errorf("syslog audit sink is not supported on windows")
+}
+
+// Record is a no-op for the unsupported SyslogSink
+func (ss *SyslogSink) Record(_ Event) error {
+	return nil
+}
@@ -0,0 +1,167 @@
+package bookmark
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/akerl/voyager/v3/cartogram"
+	"github.com/akerl/voyager/v3/internal/dirs"
+
+	"github.com/akerl/timber/v2/log"
+)
+
+const (
+	fileName = "bookmarks"
+
+	// Prefix marks an argument as a bookmark name, as in "@prod-db"
+	Prefix = "@"
+
+	nameRegexString = `^[a-zA-Z0-9_.-]+$`
+)
+
+var logger = log.NewLogger("voyager")
+
+var nameRegex = regexp.MustCompile(nameRegexString)
+
+// Bookmark is a saved set of resolution arguments
+type Bookmark struct {
+	Args    []string `json:"args"`
+	Role    string   `json:"role,omitempty"`
+	Profile string   `json:"profile,omitempty"`
+}
+
+// Set is the collection of saved bookmarks
+type Set struct {
+	Bookmarks map[string]Bookmark
+	Path      string
+}
+
+// Load reads the bookmarks file
+// A missing file results in an empty Set
+func Load() (*Set, error) {
+	s := &Set{Bookmarks: map[string]Bookmark{}}
+	filePath, err := s.getPath()
+	if err != nil {
+		return s, err
+	}
+	data, err := os.ReadFile(filePath)
+	if errors.Is(err, fs.ErrNotExist) {
+		logger.InfoMsg("no bookmarks file found")
+		return s, nil
+	} else if err != nil {
+		return s, err
+	}
+	if err := json.Unmarshal(data, &s.Bookmarks); err != nil {
+		return s, err
+	}
+	if s.Bookmarks == nil {
+		s.Bookmarks = map[string]Bookmark{}
+	}
+	return s, nil
+}
+
+// Names returns the sorted list of bookmark names
+func (s *Set) Names() []string {
+	names := []string{}
+	for name := range s.Bookmarks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Lookup finds a bookmark by name, with or without the "@" prefix
+func (s *Set) Lookup(name string) (Bookmark, error) {
+	name = strings.TrimPrefix(name, Prefix)
+	b, ok := s.Bookmarks[name]
+	if !ok {
+		return Bookmark{}, fmt.Errorf("bookmark not found: %s", name)
+	}
+	return b, nil
+}
+
+// Add saves a bookmark, replacing any existing bookmark with the same name
+func (s *Set) Add(name string, b Bookmark) error {
+	if err := validateName(name); err != nil {
+		return err
+	}
+	tfs := cartogram.TagFilterSet{}
+	if err := tfs.LoadFromArgs(b.Args); err != nil {
+		return err
+	}
+	s.Bookmarks[name] = b
+	return s.write()
+}
+
+// Remove deletes a bookmark
+func (s *Set) Remove(name string) error {
+	name = strings.TrimPrefix(name, Prefix)
+	if _, ok := s.Bookmarks[name]; !ok {
+		return fmt.Errorf("bookmark not found: %s", name)
+	}
+	delete(s.Bookmarks, name)
+	return s.write()
+}
+
+// Rename moves a bookmark to a new name, which must not already exist
+func (s *Set) Rename(oldName, newName string) error {
+	oldName = strings.TrimPrefix(oldName, Prefix)
+	newName = strings.TrimPrefix(newName, Prefix)
+	b, ok := s.Bookmarks[oldName]
+	if !ok {
+		return fmt.Errorf("bookmark not found: %s", oldName)
+	}
+	if err := validateName(newName); err != nil {
+		return err
+	}
+	if _, ok := s.Bookmarks[newName]; ok {
+		return fmt.Errorf("bookmark already exists: %s", newName)
+	}
+	delete(s.Bookmarks, oldName)
+	s.Bookmarks[newName] = b
+	return s.write()
+}
+
+// IsBookmark returns true if the args are a single bookmark reference
+func IsBookmark(args []string) bool {
+	return len(args) == 1 && strings.HasPrefix(args[0], Prefix)
+}
+
+func validateName(name string) error {
+	if !nameRegex.MatchString(name) {
+		return fmt.Errorf("invalid bookmark name: %s", name)
+	}
+	return nil
+}
+
+func (s *Set) write() error {
+	filePath, err := s.getPath()
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(s.Bookmarks, "", "  ")
+	if err != nil {
+		return err
+	}
+	logger.InfoMsgf("writing %d bookmarks to %s", len(s.Bookmarks), filePath)
+	return os.WriteFile(filePath, data, 0600)
+}
+
+func (s *Set) getPath() (string, error) {
+	if s.Path == "" {
+		dir, err := dirs.Config()
+		if err != nil {
+			return "", err
+		}
+		s.Path = path.Join(dir, fileName)
+		logger.InfoMsgf("set bookmarks path to default: %s", s.Path)
+	}
+	return s.Path, nil
+}
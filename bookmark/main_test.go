@@ -0,0 +1,35 @@
+package bookmark
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestRemoveAndRenameAcceptPrefix(t *testing.T) {
+	s := &Set{
+		Bookmarks: map[string]Bookmark{},
+		Path:      filepath.Join(t.TempDir(), "bookmarks"),
+	}
+	for _, name := range []string{"prod-db", "staging-db"} {
+		if err := s.Add(name, Bookmark{Args: []string{"env:prod"}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := s.Rename("@prod-db", "@primary-db"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Lookup("@primary-db"); err != nil {
+		t.Errorf("renamed bookmark not found: %s", err)
+	}
+	if _, ok := s.Bookmarks["@primary-db"]; ok {
+		t.Error("bookmark was renamed with its prefix")
+	}
+
+	if err := s.Remove("@staging-db"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Lookup("staging-db"); err == nil {
+		t.Error("removed bookmark still exists")
+	}
+}
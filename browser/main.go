@@ -0,0 +1,46 @@
+package browser
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+
+	"github.com/akerl/speculate/v2/creds"
+	"github.com/akerl/timber/v2/log"
+)
+
+var logger = log.NewLogger("voyager")
+
+// DefaultCommand returns the system command for opening URLs
+func DefaultCommand() []string {
+	switch runtime.GOOS {
+	case "darwin":
+		return []string{"open"}
+	case "windows":
+		return []string{"rundll32", "url.dll,FileProtocolHandler"}
+	default:
+		return []string{"xdg-open"}
+	}
+}
+
+// Open launches a URL with the provided browser command, or the system default if empty
+func Open(url, command string) error {
+	args := DefaultCommand()
+	if command != "" {
+		var err error
+		args, err = creds.StringToCommand(command)
+		if err != nil {
+			return err
+		}
+		if len(args) == 0 {
+			return fmt.Errorf("browser command is empty")
+		}
+	}
+
+	logger.InfoMsgf("opening url with %v", args)
+	cmd := exec.Command(args[0], append(args[1:], url)...)
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	return cmd.Process.Release()
+}
@@ -2,6 +2,8 @@ package cartogram
 
 import (
 	"regexp"
+	"slices"
+	"sort"
 )
 
 const (
@@ -9,6 +11,9 @@ const (
 	// Per https://docs.aws.amazon.com/IAM/latest/UserGuide/reference_iam-limits.html .
 	// role names can contain alphanumeric characters, and these symbols: +=,.@_-
 	sourceRegexString = `^(\d{12})/([a-zA-Z0-9+=,.@_-]+)$`
+
+	// AmbientSourcePath marks a source that uses the machine's own credentials
+	AmbientSourcePath = "ambient"
 )
 
 var sourceRegex = regexp.MustCompile(sourceRegexString)
@@ -29,17 +34,28 @@ type RoleSet []Role
 
 // Role holds information about authenticating to a role
 type Role struct {
-	Name    string    `json:"name"`
-	Mfa     bool      `json:"mfa"`
-	Sources SourceSet `json:"sources"`
+	Name               string    `json:"name"`
+	Mfa                bool      `json:"mfa"`
+	MaxSessionDuration int64     `json:"max_session_duration,omitempty"`
+	Sources            SourceSet `json:"sources"`
 }
 
 // SourceSet is a list of Sources
 type SourceSet []Source
 
 // Source defines the previous hop for accessing a role
+// A Source with WebIdentity set assumes the role directly using an OIDC token
 type Source struct {
-	Path string `json:"path"`
+	Path        string       `json:"path"`
+	WebIdentity *WebIdentity `json:"web_identity,omitempty"`
+}
+
+// WebIdentity defines where to read an OIDC token for AssumeRoleWithWebIdentity
+// TokenCommand is run and its output used as the token; otherwise TokenFile is read,
+// defaulting to the file named by AWS_WEB_IDENTITY_TOKEN_FILE
+type WebIdentity struct {
+	TokenFile    string   `json:"token_file,omitempty"`
+	TokenCommand []string `json:"token_command,omitempty"`
 }
 
 // Lookup finds an account in a Cartogram based on its ID
@@ -65,6 +81,24 @@ func (as AccountSet) Search(tfs TagFilterSet) AccountSet {
 	return results
 }
 
+// rankByRecent moves recently used accounts to the front, most recent first,
+// leaving the order of other accounts unchanged
+func (as AccountSet) rankByRecent(recent []string) {
+	if len(recent) == 0 {
+		return
+	}
+	rank := func(a Account) int {
+		index := slices.Index(recent, a.Account)
+		if index == -1 {
+			return len(recent)
+		}
+		return index
+	}
+	sort.SliceStable(as, func(i, j int) bool {
+		return rank(as[i]) < rank(as[j])
+	})
+}
+
 // AllProfiles returns all unique profiles found
 func (as AccountSet) AllProfiles() []string {
 	res := []string{}
@@ -74,6 +108,17 @@ func (as AccountSet) AllProfiles() []string {
 	return uniqCollect(res)
 }
 
+// Alias returns a human-friendly name for the account
+// It uses the "alias" or "name" tag when present, falling back to the account ID
+func (a Account) Alias() string {
+	for _, key := range []string{"alias", "name"} {
+		if value := a.Tags[key]; value != "" {
+			return value
+		}
+	}
+	return a.Account
+}
+
 // AllProfiles returns all unique profiles found
 func (a Account) AllProfiles() []string {
 	res := []string{}
@@ -131,12 +176,23 @@ func (s Source) AllProfiles() []string {
 
 // IsProfile returns true if the source hop is
 func (s Source) IsProfile() bool {
-	return !sourceRegex.MatchString(s.Path)
+	return !s.IsWebIdentity() && !s.IsAmbient() && !sourceRegex.MatchString(s.Path)
+}
+
+// IsAmbient returns true if the source hop uses credentials from the environment,
+// container credentials, or instance metadata
+func (s Source) IsAmbient() bool {
+	return !s.IsWebIdentity() && s.Path == AmbientSourcePath
+}
+
+// IsWebIdentity returns true if the source hop uses an OIDC token
+func (s Source) IsWebIdentity() bool {
+	return s.WebIdentity != nil
 }
 
 // Parse returns the account and role for a non-profile Path, or two empty strings
 func (s Source) Parse() (string, string) {
-	if s.IsProfile() {
+	if s.IsProfile() || s.IsWebIdentity() || s.IsAmbient() {
 		return "", ""
 	}
 	match := sourceRegex.FindStringSubmatch(s.Path)
@@ -26,8 +26,20 @@ func (cp Pack) Find(args []string) (Account, error) {
 	return cp.FindWithPrompt(args, list.Default())
 }
 
+// FindOptions allow passing structured parameters for finding an account
+// Recent lists account IDs, most recently used first, which are ranked first when prompting
+type FindOptions struct {
+	Prompt list.Prompt
+	Recent []string
+}
+
 // FindWithPrompt checks both Lookup and Search for an account with a custom prompt
 func (cp Pack) FindWithPrompt(args []string, prompt list.Prompt) (Account, error) {
+	return cp.FindWithOptions(args, FindOptions{Prompt: prompt})
+}
+
+// FindWithOptions checks both Lookup and Search for an account using the provided options
+func (cp Pack) FindWithOptions(args []string, opts FindOptions) (Account, error) {
 	var targetAccount Account
 	var err error
 	var found bool
@@ -37,7 +49,7 @@ func (cp Pack) FindWithPrompt(args []string, prompt list.Prompt) (Account, error
 		return targetAccount, err
 	}
 
-	found, targetAccount, err = cp.findMatchAccount(args, prompt)
+	found, targetAccount, err = cp.findMatchAccount(args, opts)
 	if err != nil || found {
 		return targetAccount, err
 	}
@@ -63,7 +75,7 @@ func (cp Pack) findDirectAccount(args []string) (bool, Account, error) {
 	return true, account, nil
 }
 
-func (cp Pack) findMatchAccount(args []string, prompt list.Prompt) (bool, Account, error) {
+func (cp Pack) findMatchAccount(args []string, opts FindOptions) (bool, Account, error) {
 	logger.InfoMsgf("looking for matching account using provided args: %v", args)
 
 	var account Account
@@ -81,11 +93,12 @@ func (cp Pack) findMatchAccount(args []string, prompt list.Prompt) (bool, Accoun
 		return true, accounts[0], nil
 	default:
 		logger.InfoMsgf("found %d matches", len(accounts))
+		accounts.rankByRecent(opts.Recent)
 		optSet := make(list.OptionSet, len(accounts))
 		for index, account := range accounts {
 			optSet[index] = list.Option{Name: account.Account, Metadata: account.Tags}
 		}
-		index, err := prompt.Execute("Pick an account", optSet)
+		index, err := opts.Prompt.Execute("Pick an account", optSet)
 		if err != nil {
 			return false, account, err
 		}
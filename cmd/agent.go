@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/akerl/voyager/v3/agent"
+	"github.com/akerl/voyager/v3/audit"
+	"github.com/akerl/voyager/v3/travel"
+	"github.com/akerl/voyager/v3/tty"
+
+	"github.com/spf13/cobra"
+)
+
+var agentCmd = &cobra.Command{
+	Use:   "agent",
+	Short: "Run a background agent which caches creds and MFA sessions",
+	RunE:  agentRunner,
+}
+
+var agentLockCmd = &cobra.Command{
+	Use:   "lock",
+	Short: "Drop cached creds and refuse requests until unlocked",
+	RunE:  agentLockRunner,
+}
+
+var agentUnlockCmd = &cobra.Command{
+	Use:   "unlock",
+	Short: "Unlock a locked agent",
+	RunE:  agentUnlockRunner,
+}
+
+func init() {
+	rootCmd.AddCommand(agentCmd)
+	agentCmd.AddCommand(agentLockCmd)
+	agentCmd.AddCommand(agentUnlockCmd)
+	agentCmd.Flags().String("socket", "", "Path for the agent socket, in a directory only the current user can access")
+	agentCmd.Flags().Duration("timeout", 0, "Forget cached creds after this long (0 keeps them until they expire)")
+	agentCmd.Flags().Bool("foreground", false, "Run the agent in the foreground")
+}
+
+func agentRunner(cmd *cobra.Command, _ []string) error {
+	flags := cmd.Flags()
+
+	socketPath, err := flags.GetString("socket")
+	if err != nil {
+		return err
+	}
+	if socketPath == "" {
+		socketPath, err = agent.NewSocketPath()
+		if err != nil {
+			return err
+		}
+	}
+
+	timeout, err := flags.GetDuration("timeout")
+	if err != nil {
+		return err
+	}
+
+	foreground, err := flags.GetBool("foreground")
+	if err != nil {
+		return err
+	}
+
+	if !foreground {
+		daemonArgs := []string{
+			"agent",
+			"--foreground",
+			"--socket", socketPath,
+			"--timeout", timeout.String(),
+		}
+		if storeFlag != "" {
+			daemonArgs = append(daemonArgs, "--store", storeFlag)
+		}
+		pid, err := agent.StartDaemon(socketPath, daemonArgs)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%s=%s; export %s;\n", agent.SocketEnvVar, socketPath, agent.SocketEnvVar)
+		fmt.Printf("echo Agent pid %d;\n", pid)
+		return nil
+	}
+
+	auditSink, err := audit.Default()
+	if err != nil {
+		return err
+	}
+
+	hooks, err := travel.LoadHooks("")
+	if err != nil {
+		return err
+	}
+
+	store, err := nonInteractiveStore()
+	if err != nil {
+		return fmt.Errorf("agent store: %s", err)
+	}
+
+	server := agent.Server{
+		SocketPath: socketPath,
+		Timeout:    timeout,
+		Store:      store,
+		Audit:      auditSink,
+		Hooks:      hooks,
+	}
+	if err := server.Listen(); err != nil {
+		return err
+	}
+
+	signal.Ignore(syscall.SIGHUP)
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-sigCh
+		server.Close()
+	}()
+
+	return server.Serve()
+}
+
+func agentLockRunner(_ *cobra.Command, _ []string) error {
+	client, ok := agent.FromEnv()
+	if !ok {
+		return fmt.Errorf("%s is not set", agent.SocketEnvVar)
+	}
+	passphrase, err := tty.ReadPassword("Enter lock passphrase: ")
+	if err != nil {
+		return err
+	}
+	confirmation, err := tty.ReadPassword("Confirm lock passphrase: ")
+	if err != nil {
+		return err
+	}
+	if passphrase != confirmation {
+		return fmt.Errorf("passphrases do not match")
+	}
+	if err := client.Lock(passphrase); err != nil {
+		return err
+	}
+	fmt.Println("Agent locked")
+	return nil
+}
+
+func agentUnlockRunner(_ *cobra.Command, _ []string) error {
+	client, ok := agent.FromEnv()
+	if !ok {
+		return fmt.Errorf("%s is not set", agent.SocketEnvVar)
+	}
+	passphrase, err := tty.ReadPassword("Enter lock passphrase: ")
+	if err != nil {
+		return err
+	}
+	if err := client.Unlock(passphrase); err != nil {
+		return err
+	}
+	fmt.Println("Agent unlocked")
+	return nil
+}
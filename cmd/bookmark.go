@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"github.com/akerl/voyager/v3/bookmark"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(bookmarkCmd)
+}
+
+var bookmarkCmd = &cobra.Command{
+	Use:   "bookmark",
+	Short: "manage saved targets, used as @NAME",
+}
+
+// bookmarkTarget replaces a single @NAME argument with the bookmark's args, role, and profile
+// Explicit role and profile flags take precedence over the bookmark
+func bookmarkTarget(args []string, role, profile string) ([]string, string, string, error) {
+	if !bookmark.IsBookmark(args) {
+		return args, role, profile, nil
+	}
+	set, err := bookmark.Load()
+	if err != nil {
+		return nil, "", "", err
+	}
+	b, err := set.Lookup(args[0])
+	if err != nil {
+		return nil, "", "", err
+	}
+	if role == "" {
+		role = b.Role
+	}
+	if profile == "" {
+		profile = b.Profile
+	}
+	return b.Args, role, profile, nil
+}
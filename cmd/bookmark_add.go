@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/akerl/voyager/v3/bookmark"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	bookmarkCmd.AddCommand(bookmarkAddCmd)
+	bookmarkAddCmd.Flags().StringP("role", "r", "", "Target role to save")
+	bookmarkAddCmd.Flags().String("profile", "", "Source profile to save")
+}
+
+var bookmarkAddCmd = &cobra.Command{
+	Use:   "add NAME [ARGS...]",
+	Short: "save a target as a bookmark",
+	RunE:  bookmarkAddRunner,
+}
+
+func bookmarkAddRunner(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("no bookmark name provided")
+	}
+	flags := cmd.Flags()
+
+	role, err := flags.GetString("role")
+	if err != nil {
+		return err
+	}
+	profile, err := flags.GetString("profile")
+	if err != nil {
+		return err
+	}
+
+	set, err := bookmark.Load()
+	if err != nil {
+		return err
+	}
+	err = set.Add(args[0], bookmark.Bookmark{
+		Args:    args[1:],
+		Role:    role,
+		Profile: profile,
+	})
+	if err == nil {
+		fmt.Printf("Saved bookmark: %s%s\n", bookmark.Prefix, args[0])
+	}
+	return err
+}
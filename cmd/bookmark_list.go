@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/akerl/voyager/v3/bookmark"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	bookmarkCmd.AddCommand(bookmarkListCmd)
+}
+
+var bookmarkListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "list saved bookmarks",
+	RunE:  bookmarkListRunner,
+}
+
+func bookmarkListRunner(_ *cobra.Command, _ []string) error {
+	set, err := bookmark.Load()
+	if err != nil {
+		return err
+	}
+
+	names := set.Names()
+	if len(names) == 0 {
+		fmt.Println("No bookmarks found")
+		return nil
+	}
+
+	for _, name := range names {
+		b := set.Bookmarks[name]
+		line := bookmark.Prefix + name + ": " + strings.Join(b.Args, " ")
+		if b.Role != "" {
+			line += " --role " + b.Role
+		}
+		if b.Profile != "" {
+			line += " --profile " + b.Profile
+		}
+		fmt.Println(line)
+	}
+	return nil
+}
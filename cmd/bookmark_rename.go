@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/akerl/voyager/v3/bookmark"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	bookmarkCmd.AddCommand(bookmarkRenameCmd)
+}
+
+var bookmarkRenameCmd = &cobra.Command{
+	Use:   "rename OLD NEW",
+	Short: "rename a saved bookmark",
+	RunE:  bookmarkRenameRunner,
+}
+
+func bookmarkRenameRunner(_ *cobra.Command, args []string) error {
+	if len(args) != 2 {
+		return fmt.Errorf("old and new bookmark names must be provided")
+	}
+
+	set, err := bookmark.Load()
+	if err != nil {
+		return err
+	}
+	err = set.Rename(args[0], args[1])
+	if err == nil {
+		fmt.Printf("Renamed bookmark to %s%s\n", bookmark.Prefix, args[1])
+	}
+	return err
+}
@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/akerl/voyager/v3/bookmark"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	bookmarkCmd.AddCommand(bookmarkRmCmd)
+}
+
+var bookmarkRmCmd = &cobra.Command{
+	Use:   "rm NAME",
+	Short: "delete a saved bookmark",
+	RunE:  bookmarkRmRunner,
+}
+
+func bookmarkRmRunner(_ *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("no bookmark name provided")
+	}
+
+	set, err := bookmark.Load()
+	if err != nil {
+		return err
+	}
+	err = set.Remove(args[0])
+	if err == nil {
+		fmt.Println("Deleted bookmark")
+	}
+	return err
+}
@@ -0,0 +1,275 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/akerl/voyager/v3/cartogram"
+	"github.com/akerl/voyager/v3/config"
+	"github.com/akerl/voyager/v3/format"
+	"github.com/akerl/voyager/v3/multi"
+	"github.com/akerl/voyager/v3/profiles"
+	"github.com/akerl/voyager/v3/travel"
+	"github.com/akerl/voyager/v3/yubikey"
+
+	"github.com/akerl/speculate/v2/creds"
+	"github.com/spf13/cobra"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "inspect voyager settings",
+}
+
+var configShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "print the effective settings and where each came from",
+	RunE:  configShowRunner,
+}
+
+// storeFlag names the store chain to use instead of the default
+var storeFlag string
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configShowCmd)
+	rootCmd.PersistentFlags().StringVar(&storeFlag, "store", "", "Credential store chain to use")
+}
+
+// mfaBackends provides MFA prompts by the names used in the config file
+// The tty backend uses the command's usual terminal prompt
+var mfaBackends = map[string]func(creds.MfaPrompt) creds.MfaPrompt{
+	"yubikey": func(_ creds.MfaPrompt) creds.MfaPrompt {
+		return yubikey.NewPrompt()
+	},
+	"tty": func(fallback creds.MfaPrompt) creds.MfaPrompt {
+		return fallback
+	},
+}
+
+var loadedConfig *config.Config
+
+// loadConfig reads the config file once per run
+func loadConfig() (*config.Config, error) {
+	if loadedConfig == nil {
+		c, err := config.Load()
+		if err != nil {
+			return nil, err
+		}
+		loadedConfig = c
+	}
+	return loadedConfig, nil
+}
+
+// configString returns the flag value if it was set, then the config value if set,
+// then the flag's default
+func configString(cmd *cobra.Command, flagName, configValue string) (string, error) {
+	value, err := cmd.Flags().GetString(flagName)
+	if err != nil {
+		return "", err
+	}
+	if cmd.Flags().Changed(flagName) || configValue == "" {
+		return value, nil
+	}
+	return configValue, nil
+}
+
+// mfaPrompt builds the MFA prompt from the yubikey flag or the configured backend order
+func mfaPrompt(cmd *cobra.Command, fallback creds.MfaPrompt) (creds.MfaPrompt, error) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil, err
+	}
+	useYubikey, err := cmd.Flags().GetBool("yubikey")
+	if err != nil {
+		return nil, err
+	}
+
+	names := cfg.MfaBackends
+	if useYubikey {
+		names = []string{"yubikey", "tty"}
+	}
+	if len(names) == 0 {
+		return fallback, nil
+	}
+
+	backends := []creds.MfaPrompt{}
+	for _, name := range names {
+		generator, ok := mfaBackends[name]
+		if !ok {
+			return nil, fmt.Errorf("mfa backend not found: %s", name)
+		}
+		backends = append(backends, generator(fallback))
+	}
+	if len(backends) == 1 {
+		return backends[0], nil
+	}
+	return &creds.MultiMfaPrompt{Backends: backends}, nil
+}
+
+// defaultStore returns the store chain selected by the --store flag or the stores file,
+// with keyring backends using the configured keyring settings
+func defaultStore() (profiles.Store, error) {
+	k, err := keyringStore()
+	if err != nil {
+		return nil, err
+	}
+	return buildStore(k)
+}
+
+// nonInteractiveStore returns the selected store chain without any terminal prompts,
+// for processes like the agent daemon which run detached from a terminal
+func nonInteractiveStore() (profiles.Store, error) {
+	k, err := keyringStore()
+	if err != nil {
+		return nil, err
+	}
+	k.NonInteractive = true
+	store, err := buildStore(k)
+	if err != nil {
+		return nil, err
+	}
+	return profiles.WithoutPrompts(store)
+}
+
+func buildStore(k *profiles.KeyringStore) (profiles.Store, error) {
+	storeConfig, err := loadStoreConfig()
+	if err != nil {
+		return nil, err
+	}
+	return storeConfig.Store(storeFlag, *k)
+}
+
+// loadStoreConfig reads the stores file, or builds the default chain
+// A configured store command adds an exec backend to the default chain, and cannot be
+// combined with a stores file, whose chains should use exec backends instead
+func loadStoreConfig() (*profiles.StoreConfig, error) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil, err
+	}
+	storeConfig, err := profiles.LoadStoreConfig("")
+	if err != nil {
+		return nil, err
+	}
+	if len(cfg.StoreCommand) == 0 {
+		return storeConfig, nil
+	}
+	if storeConfig.Path != "" {
+		return nil, fmt.Errorf(
+			"store_command in %s cannot be combined with the chains in %s; add an exec backend to a chain instead",
+			cfg.Path,
+			storeConfig.Path,
+		)
+	}
+	return profiles.DefaultStoreConfigWithCommand(cfg.StoreCommand), nil
+}
+
+// keyringStore returns the keyring store with the configured name and unlock settings
+func keyringStore() (*profiles.KeyringStore, error) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil, err
+	}
+	return &profiles.KeyringStore{
+		Name:           cfg.KeyringName,
+		Askpass:        cfg.KeyringAskpass,
+		UnlockLifetime: cfg.KeyringUnlockLifetime,
+	}, nil
+}
+
+// configureGrapher applies the configured default roles and preferred profiles
+func configureGrapher(g *travel.Grapher) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	g.PreferredProfiles = cfg.Profiles
+	for _, item := range cfg.Roles {
+		tfs := cartogram.TagFilterSet{}
+		if err := tfs.LoadFromArgs(item.Match); err != nil {
+			return err
+		}
+		g.DefaultRoles = append(g.DefaultRoles, travel.RoleDefault{Match: tfs, Role: item.Role})
+	}
+	return nil
+}
+
+type configSetting struct {
+	Name    string
+	Value   string
+	Default string
+}
+
+func configShowRunner(_ *cobra.Command, _ []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	pathSource := "default"
+	if os.Getenv(config.EnvVar) != "" {
+		pathSource = config.EnvVar
+	}
+	if !cfg.Found {
+		pathSource += ", not found"
+	}
+	fmt.Printf("config file: %s (%s)\n", cfg.Path, pathSource)
+
+	storeConfig, err := loadStoreConfig()
+	if err != nil {
+		return err
+	}
+	switch {
+	case storeFlag != "":
+		fmt.Printf("store chain: %s (flag)\n", storeFlag)
+	case storeConfig.Path != "":
+		fmt.Printf("store chain: %s (%s)\n", storeConfig.DefaultName(), storeConfig.Path)
+		fmt.Println("Keyring backends in the stores file override the keyring settings below")
+	default:
+		fmt.Printf("store chain: %s (default)\n", storeConfig.DefaultName())
+	}
+
+	unlockLifetime := ""
+	if cfg.KeyringUnlockLifetime != 0 {
+		unlockLifetime = cfg.KeyringUnlockLifetime.String()
+	}
+	concurrency := ""
+	if cfg.Concurrency != 0 {
+		concurrency = fmt.Sprint(cfg.Concurrency)
+	}
+	profileList := []string{}
+	for partition, profile := range cfg.Profiles {
+		profileList = append(profileList, partition+"="+profile)
+	}
+	sort.Strings(profileList)
+	roleList := []string{}
+	for _, item := range cfg.Roles {
+		roleList = append(roleList, strings.Join(item.Match, " ")+"="+item.Role)
+	}
+
+	settings := []configSetting{
+		{"prompt", cfg.Prompt, "default"},
+		{"mfa_backends", strings.Join(cfg.MfaBackends, ", "), "tty"},
+		{"profiles", strings.Join(profileList, ", "), "none"},
+		{"roles", strings.Join(roleList, ", "), "none"},
+		{"session_name", cfg.SessionName, "IAM user name"},
+		{"store_command", strings.Join(cfg.StoreCommand, " "), "none"},
+		{"keyring_name", cfg.KeyringName, profiles.DefaultKeyringName},
+		{"keyring_askpass", strings.Join(cfg.KeyringAskpass, " "), "terminal prompt"},
+		{"keyring_unlock_lifetime", unlockLifetime, profiles.DefaultUnlockLifetime.String()},
+		{"concurrency", concurrency, fmt.Sprint(multi.DefaultConcurrency)},
+		{"format", cfg.Format, format.DefaultName()},
+	}
+	for _, item := range settings {
+		if item.Value == "" {
+			fmt.Printf("%s: %s (default)\n", item.Name, item.Default)
+		} else {
+			fmt.Printf("%s: %s (config file)\n", item.Name, item.Value)
+		}
+	}
+	fmt.Println("Flags passed to a command override these settings")
+	return nil
+}
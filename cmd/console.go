@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/akerl/voyager/v3/browser"
+	"github.com/akerl/voyager/v3/console"
+
+	"github.com/spf13/cobra"
+)
+
+var consoleCmd = &cobra.Command{
+	Use:   "console [FILTERS...]",
+	Short: "Open the AWS console for a AWS account",
+	RunE:  consoleRunner,
+}
+
+func init() {
+	rootCmd.AddCommand(consoleCmd)
+	addResolveFlags(consoleCmd)
+	addSessionFlags(consoleCmd)
+	consoleCmd.Flags().String("region", "", "Override the account's region")
+	consoleCmd.Flags().String("service", "", "Service path to land on in the console")
+	consoleCmd.Flags().Duration("duration", 0, "Length of the console session, from 15m to 12h")
+	consoleCmd.Flags().Bool("print", false, "Print the URL instead of opening it")
+	consoleCmd.Flags().String("browser", "", "Command used to open the URL")
+	consoleCmd.Flags().Bool("signout", false, "Sign out of any existing console session first")
+	addTimeoutFlag(consoleCmd)
+}
+
+// revive:disable-next-line:cyclomatic
+func consoleRunner(cmd *cobra.Command, args []string) error {
+	flags := cmd.Flags()
+
+	servicePath, err := flags.GetString("service")
+	if err != nil {
+		return err
+	}
+
+	duration, err := flags.GetDuration("duration")
+	if err != nil {
+		return err
+	}
+
+	printOnly, err := flags.GetBool("print")
+	if err != nil {
+		return err
+	}
+
+	browserCmd, err := flags.GetString("browser")
+	if err != nil {
+		return err
+	}
+
+	signout, err := flags.GetBool("signout")
+	if err != nil {
+		return err
+	}
+
+	cancel, err := commandContext(cmd)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	path, err := resolvePath(cmd, args)
+	if err != nil {
+		return err
+	}
+
+	opts, err := traverseOptions(cmd)
+	if err != nil {
+		return err
+	}
+
+	c, err := path.TraverseWithContext(cmd.Context(), opts)
+	if err != nil {
+		return err
+	}
+	reportExpiration(c, opts)
+
+	consoleURL, err := console.URL(cmd.Context(), c.Creds, servicePath, duration)
+	if err != nil {
+		return err
+	}
+
+	if signout {
+		signoutURL, err := c.ToSignoutURL()
+		if err != nil {
+			return err
+		}
+		consoleURL = fmt.Sprintf("%s&redirect_uri=%s", signoutURL, url.QueryEscape(consoleURL))
+	}
+
+	if printOnly {
+		fmt.Println(consoleURL)
+		return nil
+	}
+	return browser.Open(consoleURL, browserCmd)
+}
@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/akerl/voyager/v3/audit"
+	"github.com/akerl/voyager/v3/cartogram"
+	"github.com/akerl/voyager/v3/format"
+	"github.com/akerl/voyager/v3/travel"
+	"github.com/akerl/voyager/v3/tty"
+
+	"github.com/spf13/cobra"
+)
+
+var credentialProcessCmd = &cobra.Command{
+	Use:   "credential-process ACCOUNT",
+	Short: "Print creds for use as an AWS SDK credential_process",
+	RunE:  credentialProcessRunner,
+}
+
+func init() {
+	rootCmd.AddCommand(credentialProcessCmd)
+	credentialProcessCmd.Flags().StringP("role", "r", "", "Choose target role to use")
+	credentialProcessCmd.Flags().String("profile", "", "Choose source profile to use")
+	credentialProcessCmd.Flags().BoolP("yubikey", "y", false, "Use Yubikey for MFA")
+	credentialProcessCmd.Flags().Bool("mfa-session", false, "Reuse one cached MFA session for all MFA hops")
+}
+
+func credentialProcessRunner(cmd *cobra.Command, args []string) error {
+	flags := cmd.Flags()
+
+	flagRole, err := flags.GetString("role")
+	if err != nil {
+		return err
+	}
+
+	flagProfile, err := flags.GetString("profile")
+	if err != nil {
+		return err
+	}
+
+	mfaSession, err := flags.GetBool("mfa-session")
+	if err != nil {
+		return err
+	}
+
+	pack := cartogram.Pack{}
+	if err := pack.Load(); err != nil {
+		return err
+	}
+
+	grapher := travel.Grapher{
+		Prompt: travel.NonInteractivePrompt{},
+		Pack:   pack,
+	}
+	if err := configureGrapher(&grapher); err != nil {
+		return err
+	}
+
+	path, err := grapher.Resolve(args, []string{flagRole}, []string{flagProfile})
+	if err != nil {
+		return err
+	}
+
+	opts := travel.DefaultTraverseOptions()
+	opts.Cache = &travel.FileCache{}
+	opts.MfaSession = mfaSession
+	opts.Command = cmd.Name()
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	opts.SessionName = cfg.SessionName
+	opts.Store, err = defaultStore()
+	if err != nil {
+		return err
+	}
+	opts.Audit, err = audit.Default()
+	if err != nil {
+		return err
+	}
+	opts.Hooks, err = travel.LoadHooks("")
+	if err != nil {
+		return err
+	}
+	opts.MfaPrompt, err = mfaPrompt(cmd, &tty.MfaPrompt{})
+	if err != nil {
+		return err
+	}
+
+	c, err := path.TraverseWithContext(cmd.Context(), opts)
+	if err != nil {
+		return err
+	}
+
+	formatter := format.ProcessFormatter{}
+	return formatter.Format(os.Stdout, format.Output{Creds: c})
+}
@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/akerl/voyager/v3/runner"
+
+	"github.com/spf13/cobra"
+)
+
+var execCmd = &cobra.Command{
+	Use:   "exec [FILTERS...] -- COMMAND [ARGS...]",
+	Short: "Run a command with creds for a AWS account",
+	RunE:  execRunner,
+}
+
+func init() {
+	rootCmd.AddCommand(execCmd)
+	addResolveFlags(execCmd)
+	addSessionFlags(execCmd)
+	addTimeoutFlag(execCmd)
+}
+
+func execRunner(cmd *cobra.Command, args []string) error {
+	dash := cmd.ArgsLenAtDash()
+	if dash == -1 || dash == len(args) {
+		return fmt.Errorf("command must be provided after --")
+	}
+	filters, command := args[:dash], args[dash:]
+
+	cancel, err := commandContext(cmd)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	path, err := resolvePath(cmd, filters)
+	if err != nil {
+		return err
+	}
+
+	opts, err := traverseOptions(cmd)
+	if err != nil {
+		return err
+	}
+
+	c, err := path.TraverseWithContext(cmd.Context(), opts)
+	if err != nil {
+		return err
+	}
+
+	code, err := runner.Run(command, c.ToEnviron())
+	if err != nil {
+		return err
+	}
+	if code != 0 {
+		return ExitError{Code: code}
+	}
+	return nil
+}
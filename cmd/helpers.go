@@ -0,0 +1,286 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/akerl/voyager/v3/agent"
+	"github.com/akerl/voyager/v3/audit"
+	"github.com/akerl/voyager/v3/cartogram"
+	"github.com/akerl/voyager/v3/travel"
+
+	"github.com/akerl/input/list"
+	"github.com/akerl/speculate/v2/creds"
+	"github.com/spf13/cobra"
+)
+
+// ExitError indicates that voyager should exit with a specific code
+type ExitError struct {
+	Code int
+}
+
+func (e ExitError) Error() string {
+	return fmt.Sprintf("exit status %d", e.Code)
+}
+
+func addResolveFlags(cmd *cobra.Command) {
+	cmd.Flags().StringP("role", "r", "", "Choose target role to use")
+	cmd.Flags().String("profile", "", "Choose source profile to use")
+	cmd.Flags().StringP("prompt", "p", "", "Choose prompt to use")
+	cmd.Flags().BoolP("yubikey", "y", false, "Use Yubikey for MFA")
+	cmd.Flags().Bool("recent", false, "Pick from recently used targets")
+}
+
+func addDurationFlags(cmd *cobra.Command) {
+	cmd.Flags().Duration("duration", 0, "Requested session length for assumed roles")
+	cmd.Flags().Bool("strict-duration", false, "Fail instead of shortening durations roles cannot provide")
+}
+
+func addSessionFlags(cmd *cobra.Command) {
+	addSessionFlagsWithMfaSession(cmd, false)
+}
+
+func addSessionFlagsWithMfaSession(cmd *cobra.Command, mfaSession bool) {
+	cmd.Flags().StringArray("tag", []string{}, "Session tag to send on each hop, as KEY=VALUE (VALUE may be a template)")
+	cmd.Flags().StringSlice("transitive-tag", []string{}, "Session tag keys to mark as transitive")
+	cmd.Flags().StringSlice("account-tag", []string{}, "Cartogram account tags to send as session tags")
+	cmd.Flags().String("source-identity", "", "Source identity to set on each hop (may be a template)")
+	cmd.Flags().Bool("mfa-session", mfaSession, "Reuse one cached MFA session for all MFA hops")
+}
+
+func resolvePath(cmd *cobra.Command, args []string) (travel.Path, error) {
+	flags := cmd.Flags()
+
+	flagRole, err := flags.GetString("role")
+	if err != nil {
+		return travel.Path{}, err
+	}
+
+	flagProfile, err := flags.GetString("profile")
+	if err != nil {
+		return travel.Path{}, err
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return travel.Path{}, err
+	}
+
+	promptFlag, err := configString(cmd, "prompt", cfg.Prompt)
+	if err != nil {
+		return travel.Path{}, err
+	}
+	promptGenerator, ok := list.Types[promptFlag]
+	if !ok {
+		return travel.Path{}, fmt.Errorf("prompt type not found: %s", promptFlag)
+	}
+	prompt := promptGenerator()
+
+	flagRecent, err := flags.GetBool("recent")
+	if err != nil {
+		return travel.Path{}, err
+	}
+
+	pack := cartogram.Pack{}
+	if err := pack.Load(); err != nil {
+		return travel.Path{}, err
+	}
+
+	args, flagRole, flagProfile, err = bookmarkTarget(args, flagRole, flagProfile)
+	if err != nil {
+		return travel.Path{}, err
+	}
+
+	hist := loadHistory()
+	args, flagRole, flagProfile, err = historyTarget(hist, prompt, flagRecent, args, flagRole, flagProfile)
+	if err != nil {
+		return travel.Path{}, err
+	}
+
+	grapher := travel.Grapher{
+		Prompt: prompt,
+		Pack:   pack,
+		Recent: hist.Accounts(),
+	}
+	if err := configureGrapher(&grapher); err != nil {
+		return travel.Path{}, err
+	}
+
+	resolveOpts := travel.ResolveOptions{
+		Args:         args,
+		RoleNames:    []string{flagRole},
+		ProfileNames: []string{flagProfile},
+	}
+	if flags.Lookup("region") != nil {
+		resolveOpts.Region, err = flags.GetString("region")
+		if err != nil {
+			return travel.Path{}, err
+		}
+	}
+
+	path, err := grapher.ResolveWithContext(cmd.Context(), resolveOpts)
+	if err != nil {
+		return travel.Path{}, err
+	}
+	recordHistory(hist, path)
+	return path, nil
+}
+
+func addTimeoutFlag(cmd *cobra.Command) {
+	cmd.Flags().Duration("timeout", 0, "Abort if not finished within this duration")
+}
+
+// commandContext sets a context on the command which is cancelled on interrupt
+// or when the timeout flag expires
+func commandContext(cmd *cobra.Command) (context.CancelFunc, error) {
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	cancel := stop
+
+	timeout, err := cmd.Flags().GetDuration("timeout")
+	if err != nil {
+		stop()
+		return nil, err
+	}
+	if timeout != 0 {
+		var timeoutCancel context.CancelFunc
+		ctx, timeoutCancel = context.WithTimeout(ctx, timeout)
+		cancel = func() {
+			timeoutCancel()
+			stop()
+		}
+	}
+
+	cmd.SetContext(ctx)
+	return cancel, nil
+}
+
+func traverseOptions(cmd *cobra.Command) (travel.TraverseOptions, error) {
+	opts := travel.DefaultTraverseOptions()
+	opts.Command = cmd.Name()
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return opts, err
+	}
+	opts.SessionName = cfg.SessionName
+	opts.Store, err = defaultStore()
+	if err != nil {
+		return opts, err
+	}
+
+	opts.Audit, err = audit.Default()
+	if err != nil {
+		return opts, err
+	}
+	opts.Hooks, err = travel.LoadHooks("")
+	if err != nil {
+		return opts, err
+	}
+
+	opts.MfaPrompt, err = mfaPrompt(cmd, &creds.DefaultMfaPrompt{})
+	if err != nil {
+		return opts, err
+	}
+	if client, ok := agent.FromEnv(); ok {
+		opts.Delegate = client
+	}
+
+	// Ambient credentials come from the environment, so they're captured before it is cleared
+	opts.Ambient = travel.DefaultAmbientProvider()
+	if err := clearEnvironment(); err != nil {
+		return opts, err
+	}
+
+	// console has its own --duration for the console session, without the role duration flags
+	if cmd.Flags().Lookup("strict-duration") != nil {
+		duration, err := cmd.Flags().GetDuration("duration")
+		if err != nil {
+			return opts, err
+		}
+		opts.Lifetime = int64(duration.Seconds())
+		opts.StrictLifetime, err = cmd.Flags().GetBool("strict-duration")
+		if err != nil {
+			return opts, err
+		}
+	}
+	if cmd.Flags().Lookup("retries") != nil {
+		opts.Retry.Attempts, err = cmd.Flags().GetInt("retries")
+		if err != nil {
+			return opts, err
+		}
+	}
+	if cmd.Flags().Lookup("tag") != nil {
+		if err := parseSessionFlags(cmd, &opts); err != nil {
+			return opts, err
+		}
+	}
+	return opts, nil
+}
+
+func parseSessionFlags(cmd *cobra.Command, opts *travel.TraverseOptions) error {
+	flags := cmd.Flags()
+
+	tags, err := flags.GetStringArray("tag")
+	if err != nil {
+		return err
+	}
+	if len(tags) != 0 {
+		opts.SessionTags = map[string]string{}
+	}
+	for _, tag := range tags {
+		key, value, ok := strings.Cut(tag, "=")
+		if !ok || key == "" {
+			return fmt.Errorf("session tag must be KEY=VALUE: %s", tag)
+		}
+		opts.SessionTags[key] = value
+	}
+
+	opts.TransitiveTagKeys, err = flags.GetStringSlice("transitive-tag")
+	if err != nil {
+		return err
+	}
+	opts.AccountTagKeys, err = flags.GetStringSlice("account-tag")
+	if err != nil {
+		return err
+	}
+	opts.SourceIdentity, err = flags.GetString("source-identity")
+	if err != nil {
+		return err
+	}
+	opts.MfaSession, err = flags.GetBool("mfa-session")
+	return err
+}
+
+// clearEnvironment removes credential env vars, so that commands run by voyager
+// only see the credentials it provides
+func clearEnvironment() error {
+	for varName := range creds.Translations["envvar"] {
+		err := os.Unsetenv(varName)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func reportExpiration(c travel.Creds, opts travel.TraverseOptions) {
+	if opts.Lifetime == 0 || c.Expiration.IsZero() {
+		return
+	}
+	requested := time.Duration(opts.Lifetime) * time.Second
+	actual := time.Until(c.Expiration).Round(time.Minute)
+	if actual < requested-time.Minute {
+		fmt.Fprintf(
+			os.Stderr,
+			"Requested duration of %s was shortened; credentials expire at %s (in %s)\n",
+			requested,
+			c.Expiration.Local().Format(time.RFC3339),
+			actual,
+		)
+	}
+}
@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/akerl/voyager/v3/history"
+	"github.com/akerl/voyager/v3/travel"
+
+	"github.com/akerl/input/list"
+	"github.com/spf13/cobra"
+)
+
+// lastTargetArg repeats the most recent target, like "cd -"
+const lastTargetArg = "-"
+
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "List recently used targets",
+	RunE:  historyRunner,
+}
+
+func init() {
+	rootCmd.AddCommand(historyCmd)
+	historyCmd.Flags().IntP("count", "n", 20, "Number of targets to list (0 for all)")
+	historyCmd.Flags().Bool("all", false, "List every entry instead of unique targets")
+}
+
+func historyRunner(cmd *cobra.Command, _ []string) error {
+	flags := cmd.Flags()
+	count, err := flags.GetInt("count")
+	if err != nil {
+		return err
+	}
+	all, err := flags.GetBool("all")
+	if err != nil {
+		return err
+	}
+
+	hist, err := history.Load()
+	if err != nil {
+		return err
+	}
+
+	var entries []history.Entry
+	if all {
+		for i := len(hist.Entries) - 1; i >= 0; i-- {
+			if count != 0 && len(entries) == count {
+				break
+			}
+			entries = append(entries, hist.Entries[i])
+		}
+	} else {
+		entries = hist.Recent(count)
+	}
+
+	if len(entries) == 0 {
+		fmt.Println("No history found")
+		return nil
+	}
+	for _, item := range entries {
+		fmt.Printf("%s  %s\n", item.Time.Local().Format(time.DateTime), item.Target())
+	}
+	return nil
+}
+
+// loadHistory reads the history file, warning rather than failing on errors
+func loadHistory() *history.History {
+	hist, err := history.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: failed to read history: %s\n", err)
+		return &history.History{}
+	}
+	return hist
+}
+
+// historyTarget replaces the args, role, and profile with a target from the history
+// when the last target is requested with "-" or a recent target with --recent
+// Explicit role and profile flags take precedence over the history entry
+func historyTarget(
+	hist *history.History,
+	prompt list.Prompt,
+	recent bool,
+	args []string,
+	role, profile string,
+) ([]string, string, string, error) {
+	var entry history.Entry
+	switch {
+	case recent:
+		if len(args) != 0 {
+			return nil, "", "", fmt.Errorf("--recent cannot be combined with account arguments")
+		}
+		entries := hist.Recent(0)
+		if len(entries) == 0 {
+			return nil, "", "", fmt.Errorf("no history found")
+		}
+		optSet := make(list.OptionSet, len(entries))
+		for index, item := range entries {
+			optSet[index] = list.Option{
+				Name:     item.Target(),
+				Metadata: map[string]string{"last used": item.Time.Local().Format(time.DateTime)},
+			}
+		}
+		index, err := prompt.Execute("Pick a recent target", optSet)
+		if err != nil {
+			return nil, "", "", err
+		}
+		entry = entries[index]
+	case len(args) == 1 && args[0] == lastTargetArg:
+		var ok bool
+		entry, ok = hist.Last()
+		if !ok {
+			return nil, "", "", fmt.Errorf("no history found")
+		}
+	default:
+		return args, role, profile, nil
+	}
+
+	if role == "" {
+		role = entry.Role
+	}
+	if profile == "" {
+		profile = entry.Profile
+	}
+	return []string{entry.Account}, role, profile, nil
+}
+
+// recordHistory adds a resolved path to the history, warning rather than failing on errors
+func recordHistory(hist *history.History, path travel.Path) {
+	target := path[len(path)-1]
+	err := hist.Add(history.Entry{
+		Account: target.Account.Account,
+		Role:    target.Role,
+		Profile: path[0].Origin(),
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: failed to write history: %s\n", err)
+	}
+}
@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/akerl/voyager/v3/profiles"
+
+	"github.com/spf13/cobra"
+)
+
+var lockCmd = &cobra.Command{
+	Use:   "lock",
+	Short: "Forget cached passphrases for file keyrings in the store chain",
+	RunE:  lockRunner,
+}
+
+func init() {
+	rootCmd.AddCommand(lockCmd)
+}
+
+func lockRunner(_ *cobra.Command, _ []string) error {
+	store, err := defaultStore()
+	if err != nil {
+		return err
+	}
+	if err := profiles.LockKeyrings(store); err != nil {
+		return err
+	}
+	fmt.Println("Keyrings locked")
+	return nil
+}
@@ -3,8 +3,6 @@ package cmd
 import (
 	"fmt"
 
-	"github.com/akerl/voyager/v3/profiles"
-
 	"github.com/akerl/input/list"
 	"github.com/spf13/cobra"
 )
@@ -25,7 +23,10 @@ func profilesAddRunner(_ *cobra.Command, args []string) error {
 		inputProfile = args[0]
 	}
 
-	store := profiles.NewDefaultStore()
+	store, err := defaultStore()
+	if err != nil {
+		return err
+	}
 
 	allProfiles, err := getAllProfiles()
 	if err != nil {
@@ -4,7 +4,6 @@ import (
 	"fmt"
 
 	"github.com/akerl/voyager/v3/confirm"
-	"github.com/akerl/voyager/v3/profiles"
 
 	"github.com/spf13/cobra"
 )
@@ -25,7 +24,10 @@ func profilesDeleteRunner(_ *cobra.Command, args []string) error {
 	}
 	profile := args[0]
 
-	store := profiles.NewDefaultStore()
+	store, err := defaultStore()
+	if err != nil {
+		return err
+	}
 
 	check := store.Check(profile)
 	if !check {
@@ -33,7 +35,7 @@ func profilesDeleteRunner(_ *cobra.Command, args []string) error {
 		return nil
 	}
 
-	err := confirm.Text(
+	err = confirm.Text(
 		"this is a destructive operation",
 		fmt.Sprintf("This will delete the following profile: %s", profile),
 	)
@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/akerl/voyager/v3/profiles"
+
+	"github.com/spf13/cobra"
+)
+
+// importSources provides the stores that profiles can be imported from
+var importSources = map[string]func() profiles.Store{
+	"aws-credentials": func() profiles.Store {
+		return &profiles.CredentialsFileStore{}
+	},
+}
+
+func init() {
+	profilesCmd.AddCommand(profilesImportCmd)
+	profilesImportCmd.Flags().String("from", "", "Source to import from ("+strings.Join(importSourceNames(), ", ")+")")
+	profilesImportCmd.Flags().Bool("remove", false, "Remove each profile from the source after importing it")
+}
+
+var profilesImportCmd = &cobra.Command{
+	Use:   "import [PROFILE...]",
+	Short: "copy AWS credentials from another source",
+	RunE:  profilesImportRunner,
+}
+
+func importSourceNames() []string {
+	res := []string{}
+	for k := range importSources {
+		res = append(res, k)
+	}
+	sort.Strings(res)
+	return res
+}
+
+func profilesImportRunner(cmd *cobra.Command, args []string) error {
+	flags := cmd.Flags()
+
+	from, err := flags.GetString("from")
+	if err != nil {
+		return err
+	}
+	generator, ok := importSources[from]
+	if !ok {
+		return fmt.Errorf("import source not found: %s", from)
+	}
+	source := generator()
+
+	remove, err := flags.GetBool("remove")
+	if err != nil {
+		return err
+	}
+
+	names := args
+	if len(names) == 0 {
+		if lister, ok := source.(profiles.ListableStore); ok {
+			names, err = lister.List()
+		} else {
+			names, err = getAllProfiles()
+		}
+		if err != nil {
+			return err
+		}
+	}
+	found := profiles.BulkCheck(source, names)
+	if len(found) == 0 {
+		fmt.Println("No matching profiles found")
+		return nil
+	}
+	sort.Strings(found)
+
+	// Profiles are imported into the keyring rather than the store chain, which may
+	// include the source itself and would then delete the imported profiles on --remove
+	dest, err := keyringStore()
+	if err != nil {
+		return err
+	}
+
+	for _, name := range found {
+		creds, err := source.Lookup(name)
+		if err != nil {
+			return err
+		}
+		if err := dest.Write(name, creds); err != nil {
+			return err
+		}
+		fmt.Printf("Imported profile: %s (%s)\n", name, creds.AccessKeyID)
+		if remove {
+			imported, err := dest.Lookup(name)
+			if err != nil || imported.AccessKeyID != creds.AccessKeyID || imported.SecretAccessKey != creds.SecretAccessKey {
+				return fmt.Errorf("failed to verify %s in the keyring, so it was not removed from %s", name, from)
+			}
+			if err := source.Delete(name); err != nil {
+				return err
+			}
+			fmt.Printf("Removed %s from %s\n", name, from)
+		}
+	}
+	return nil
+}
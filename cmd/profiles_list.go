@@ -25,7 +25,10 @@ func profilesListRunner(_ *cobra.Command, _ []string) error {
 		return err
 	}
 
-	store := profiles.NewDefaultStore()
+	store, err := defaultStore()
+	if err != nil {
+		return err
+	}
 	existing := profiles.BulkCheck(store, allProfiles)
 
 	if len(existing) == 0 {
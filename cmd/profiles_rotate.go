@@ -40,9 +40,15 @@ func profilesRotateRunner(cmd *cobra.Command, args []string) error {
 		mfaPrompt = &creds.DefaultMfaPrompt{}
 	}
 
+	store, err := defaultStore()
+	if err != nil {
+		return err
+	}
+
 	r := rotate.Rotator{
 		InputProfile: inputProfile,
 		MfaPrompt:    mfaPrompt,
+		Store:        store,
 	}
 	return r.Execute()
 }
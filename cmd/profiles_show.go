@@ -3,8 +3,6 @@ package cmd
 import (
 	"fmt"
 
-	"github.com/akerl/voyager/v3/profiles"
-
 	"github.com/spf13/cobra"
 )
 
@@ -24,7 +22,10 @@ func profilesShowRunner(_ *cobra.Command, args []string) error {
 	}
 	profile := args[0]
 
-	store := profiles.NewDefaultStore()
+	store, err := defaultStore()
+	if err != nil {
+		return err
+	}
 
 	check := store.Check(profile)
 	if !check {
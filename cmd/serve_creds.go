@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/akerl/voyager/v3/serve"
+
+	"github.com/spf13/cobra"
+)
+
+var serveCredsCmd = &cobra.Command{
+	Use:   "serve-creds [FILTERS...]",
+	Short: "Serve auto-refreshing creds for an AWS account over a local endpoint",
+	RunE:  serveCredsRunner,
+}
+
+func init() {
+	rootCmd.AddCommand(serveCredsCmd)
+	addResolveFlags(serveCredsCmd)
+	// The server refreshes unattended, so reuse an MFA session to prompt as rarely as possible
+	addSessionFlagsWithMfaSession(serveCredsCmd, true)
+	serveCredsCmd.Flags().String("address", "127.0.0.1:0", "Address for the endpoint to listen on")
+}
+
+func serveCredsRunner(cmd *cobra.Command, args []string) error {
+	address, err := cmd.Flags().GetString("address")
+	if err != nil {
+		return err
+	}
+
+	path, err := resolvePath(cmd, args)
+	if err != nil {
+		return err
+	}
+
+	opts, err := traverseOptions(cmd)
+	if err != nil {
+		return err
+	}
+
+	server := serve.Server{
+		Path:    path,
+		Options: opts,
+		Address: address,
+	}
+	if err := server.Start(); err != nil {
+		return err
+	}
+
+	envVars := server.EnvVars()
+	names := []string{}
+	for k := range envVars {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	fmt.Println("unset AWS_ACCESS_KEY_ID AWS_SECRET_ACCESS_KEY AWS_SESSION_TOKEN AWS_SECURITY_TOKEN")
+	for _, k := range names {
+		fmt.Printf("export %s=%s\n", k, envVars[k])
+	}
+	fmt.Printf("# serving credentials for %s until interrupted\n", path[len(path)-1].Account.Account)
+
+	return server.Serve()
+}
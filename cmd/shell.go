@@ -0,0 +1,198 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/akerl/voyager/v3/format"
+	"github.com/akerl/voyager/v3/runner"
+	"github.com/akerl/voyager/v3/travel"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	shellStateVar  = "VOYAGER_SHELL"
+	defaultShell   = "/bin/sh"
+	shellWarnAhead = 10 * time.Minute
+	shellRecheck   = time.Minute
+)
+
+var tagEnvRegex = regexp.MustCompile(`[^A-Z0-9_]`)
+
+var shellCmd = &cobra.Command{
+	Use:   "shell [FILTERS...]",
+	Short: "Start a subshell with creds for a AWS account",
+	RunE:  shellRunner,
+}
+
+func init() {
+	rootCmd.AddCommand(shellCmd)
+	addResolveFlags(shellCmd)
+	addSessionFlags(shellCmd)
+	shellCmd.Flags().Bool("refresh", false, "Print refreshed creds for the current voyager shell")
+	shellCmd.Flags().StringP(
+		"format",
+		"f",
+		"",
+		"Output format for --refresh ("+strings.Join(format.Names(), ", ")+")",
+	)
+}
+
+func shellRunner(cmd *cobra.Command, args []string) error {
+	refresh, err := cmd.Flags().GetBool("refresh")
+	if err != nil {
+		return err
+	}
+
+	statePath := os.Getenv(shellStateVar)
+	if refresh {
+		return shellRefresh(cmd, statePath)
+	}
+	if statePath != "" {
+		return fmt.Errorf(
+			"already inside a voyager shell for %s; exit it before starting another, "+
+				"or refresh it with: eval \"$(voyager shell --refresh)\"",
+			os.Getenv("VOYAGER_ALIAS"),
+		)
+	}
+
+	path, err := resolvePath(cmd, args)
+	if err != nil {
+		return err
+	}
+
+	opts, err := traverseOptions(cmd)
+	if err != nil {
+		return err
+	}
+
+	c, err := path.TraverseWithContext(cmd.Context(), opts)
+	if err != nil {
+		return err
+	}
+
+	stateFile, err := os.CreateTemp("", "voyager-shell")
+	if err != nil {
+		return err
+	}
+	stateFile.Close()
+	defer os.Remove(stateFile.Name())
+	if err := writeShellState(stateFile.Name(), c.Expiration); err != nil {
+		return err
+	}
+
+	env := append(c.ToEnviron(), shellEnvVars(path, stateFile.Name())...)
+	shell := os.Getenv("SHELL")
+	if shell == "" {
+		shell = defaultShell
+	}
+
+	alias := path[len(path)-1].Account.Alias()
+	fmt.Fprintf(os.Stderr, "Starting voyager shell for %s; exit to return\n", alias)
+	go shellExpiryWarning(stateFile.Name(), alias)
+
+	code, err := runner.Run([]string{shell}, env)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Leaving voyager shell for %s\n", alias)
+	if code != 0 {
+		return ExitError{Code: code}
+	}
+	return nil
+}
+
+func shellEnvVars(path travel.Path, statePath string) []string {
+	target := path[len(path)-1]
+	env := []string{
+		shellStateVar + "=" + statePath,
+		"VOYAGER_ACCOUNT=" + target.Account.Account,
+		"VOYAGER_ROLE=" + target.Role,
+		"VOYAGER_ALIAS=" + target.Account.Alias(),
+		"VOYAGER_PROFILE=" + path[0].Origin(),
+	}
+	for k, v := range target.Account.Tags {
+		name := tagEnvRegex.ReplaceAllString(strings.ToUpper(k), "_")
+		env = append(env, "VOYAGER_TAG_"+name+"="+v)
+	}
+	return env
+}
+
+func shellRefresh(cmd *cobra.Command, statePath string) error {
+	if statePath == "" {
+		return fmt.Errorf("--refresh can only be used inside a voyager shell")
+	}
+
+	formatFlag, err := cmd.Flags().GetString("format")
+	if err != nil {
+		return err
+	}
+	formatter, err := format.New(formatFlag, format.Options{})
+	if err != nil {
+		return err
+	}
+
+	flags := cmd.Flags()
+	for flag, envVar := range map[string]string{"role": "VOYAGER_ROLE", "profile": "VOYAGER_PROFILE"} {
+		if err := flags.Set(flag, os.Getenv(envVar)); err != nil {
+			return err
+		}
+	}
+
+	path, err := resolvePath(cmd, []string{os.Getenv("VOYAGER_ACCOUNT")})
+	if err != nil {
+		return err
+	}
+
+	opts, err := traverseOptions(cmd)
+	if err != nil {
+		return err
+	}
+
+	c, err := path.TraverseWithContext(cmd.Context(), opts)
+	if err != nil {
+		return err
+	}
+
+	if err := writeShellState(statePath, c.Expiration); err != nil {
+		return err
+	}
+	return formatter.Format(os.Stdout, format.Output{Creds: c})
+}
+
+func writeShellState(statePath string, expiration time.Time) error {
+	return os.WriteFile(statePath, []byte(expiration.Format(time.RFC3339)), 0600)
+}
+
+func readShellState(statePath string) (time.Time, error) {
+	data, err := os.ReadFile(statePath)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Parse(time.RFC3339, strings.TrimSpace(string(data)))
+}
+
+func shellExpiryWarning(statePath, alias string) {
+	var warned time.Time
+	for {
+		expiration, err := readShellState(statePath)
+		if err != nil || expiration.IsZero() {
+			return
+		}
+		if expiration != warned && time.Until(expiration) < shellWarnAhead {
+			fmt.Fprintf(
+				os.Stderr,
+				"\nvoyager: credentials for %s expire at %s; "+
+					"refresh with: eval \"$(voyager shell --refresh)\"\n",
+				alias,
+				expiration.Local().Format(time.Kitchen),
+			)
+			warned = expiration
+		}
+		time.Sleep(shellRecheck)
+	}
+}
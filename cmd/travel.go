@@ -1,102 +1,96 @@
 package cmd
 
 import (
-	"fmt"
+	"os"
+	"strings"
 
-	"github.com/akerl/voyager/v3/cartogram"
-	"github.com/akerl/voyager/v3/travel"
-	"github.com/akerl/voyager/v3/yubikey"
+	"github.com/akerl/voyager/v3/format"
 
-	"github.com/akerl/input/list"
-	"github.com/akerl/speculate/v2/creds"
 	"github.com/spf13/cobra"
 )
 
 var travelCmd = &cobra.Command{
-	Use:   "travel",
+	Use:   "travel [FILTERS...]",
 	Short: "Resolve creds for a AWS account",
 	RunE:  travelRunner,
 }
 
 func init() {
 	rootCmd.AddCommand(travelCmd)
-	travelCmd.Flags().StringP("role", "r", "", "Choose target role to use")
-	travelCmd.Flags().String("profile", "", "Choose source profile to use")
-	travelCmd.Flags().StringP("prompt", "p", "", "Choose prompt to use")
-	travelCmd.Flags().BoolP("yubikey", "y", false, "Use Yubikey for MFA")
+	addResolveFlags(travelCmd)
+	addSessionFlags(travelCmd)
+	addTimeoutFlag(travelCmd)
+	addDurationFlags(travelCmd)
 	travelCmd.Flags().String("service", "", "Service path for console URL")
+	travelCmd.Flags().StringP(
+		"format",
+		"f",
+		"",
+		"Output format ("+strings.Join(format.Names(), ", ")+")",
+	)
+	travelCmd.Flags().String("section", "voyager", "Section name for credentials format")
+	travelCmd.Flags().Bool("no-console", false, "Skip generating a console URL")
 }
 
-// revive:disable-next-line:cyclomatic
 func travelRunner(cmd *cobra.Command, args []string) error {
 	flags := cmd.Flags()
 
-	flagRole, err := flags.GetString("role")
+	servicePath, err := flags.GetString("service")
 	if err != nil {
 		return err
 	}
 
-	flagProfile, err := flags.GetString("profile")
+	cfg, err := loadConfig()
 	if err != nil {
 		return err
 	}
-
-	promptFlag, err := flags.GetString("prompt")
+	formatFlag, err := configString(cmd, "format", cfg.Format)
 	if err != nil {
 		return err
 	}
-	promptGenerator, ok := list.Types[promptFlag]
-	if !ok {
-		return fmt.Errorf("prompt type not found: %s", promptFlag)
-	}
-	prompt := promptGenerator()
-
-	useYubikey, err := flags.GetBool("yubikey")
+	section, err := flags.GetString("section")
 	if err != nil {
 		return err
 	}
-
-	servicePath, err := flags.GetString("service")
+	formatter, err := format.New(formatFlag, format.Options{Section: section})
 	if err != nil {
 		return err
 	}
 
-	pack := cartogram.Pack{}
-	if err := pack.Load(); err != nil {
+	noConsole, err := flags.GetBool("no-console")
+	if err != nil {
 		return err
 	}
 
-	grapher := travel.Grapher{
-		Prompt: prompt,
-		Pack:   pack,
-	}
-
-	path, err := grapher.Resolve(args, []string{flagRole}, []string{flagProfile})
+	cancel, err := commandContext(cmd)
 	if err != nil {
 		return err
 	}
+	defer cancel()
 
-	opts := travel.DefaultTraverseOptions()
-	if useYubikey {
-		opts.MfaPrompt = &creds.MultiMfaPrompt{Backends: []creds.MfaPrompt{
-			yubikey.NewPrompt(),
-			&creds.DefaultMfaPrompt{},
-		}}
+	path, err := resolvePath(cmd, args)
+	if err != nil {
+		return err
 	}
 
-	c, err := path.TraverseWithOptions(opts)
+	opts, err := traverseOptions(cmd)
 	if err != nil {
 		return err
 	}
 
-	for _, line := range c.ToEnvVars() {
-		fmt.Println(line)
-	}
-	url, err := c.ToCustomConsoleURL(servicePath)
+	c, err := path.TraverseWithContext(cmd.Context(), opts)
 	if err != nil {
 		return err
 	}
-	fmt.Printf("# %s\n", url)
+	reportExpiration(c, opts)
+
+	output := format.Output{Creds: c}
+	if !noConsole {
+		output.ConsoleURL, err = c.ToCustomConsoleURL(servicePath)
+		if err != nil {
+			return err
+		}
+	}
 
-	return nil
+	return formatter.Format(os.Stdout, output)
 }
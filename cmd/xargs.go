@@ -7,10 +7,8 @@ import (
 	"github.com/akerl/voyager/v3/cartogram"
 	"github.com/akerl/voyager/v3/multi"
 	"github.com/akerl/voyager/v3/travel"
-	"github.com/akerl/voyager/v3/yubikey"
 
 	"github.com/akerl/input/list"
-	"github.com/akerl/speculate/v2/creds"
 	"github.com/spf13/cobra"
 )
 
@@ -28,6 +26,11 @@ func init() {
 	xargsCmd.Flags().BoolP("yubikey", "y", false, "Use Yubikey for MFA")
 	xargsCmd.Flags().StringP("command", "c", "", "Command to execute")
 	xargsCmd.Flags().Bool("skipconfirm", false, "Skip confirmation prompt")
+	xargsCmd.Flags().Int("concurrency", multi.DefaultConcurrency, "Number of accounts to process at once")
+	xargsCmd.Flags().Int("retries", travel.DefaultRetryAttempts, "Attempts for each hop when throttled or failing transiently")
+	addDurationFlags(xargsCmd)
+	addSessionFlags(xargsCmd)
+	addTimeoutFlag(xargsCmd)
 }
 
 // revive:disable-next-line:cyclomatic
@@ -44,7 +47,12 @@ func xargsRunner(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	promptFlag, err := flags.GetString("prompt")
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	promptFlag, err := configString(cmd, "prompt", cfg.Prompt)
 	if err != nil {
 		return err
 	}
@@ -54,11 +62,6 @@ func xargsRunner(cmd *cobra.Command, args []string) error {
 	}
 	prompt := promptGenerator()
 
-	useYubikey, err := flags.GetBool("yubikey")
-	if err != nil {
-		return err
-	}
-
 	commandStr, err := flags.GetString("command")
 	if err != nil {
 		return err
@@ -72,6 +75,30 @@ func xargsRunner(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	concurrency, err := flags.GetInt("concurrency")
+	if err != nil {
+		return err
+	}
+	if !flags.Changed("concurrency") && cfg.Concurrency != 0 {
+		concurrency = cfg.Concurrency
+	}
+
+	args, flagRole, flagProfile, err = bookmarkTarget(args, flagRole, flagProfile)
+	if err != nil {
+		return err
+	}
+
+	opts, err := traverseOptions(cmd)
+	if err != nil {
+		return err
+	}
+
+	cancel, err := commandContext(cmd)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
 	pack := cartogram.Pack{}
 	if err := pack.Load(); err != nil {
 		return err
@@ -81,13 +108,8 @@ func xargsRunner(cmd *cobra.Command, args []string) error {
 		Prompt: prompt,
 		Pack:   pack,
 	}
-
-	opts := travel.DefaultTraverseOptions()
-	if useYubikey {
-		opts.MfaPrompt = &creds.MultiMfaPrompt{Backends: []creds.MfaPrompt{
-			yubikey.NewPrompt(),
-			&creds.DefaultMfaPrompt{},
-		}}
+	if err := configureGrapher(&grapher); err != nil {
+		return err
 	}
 
 	processor := multi.Processor{
@@ -97,11 +119,12 @@ func xargsRunner(cmd *cobra.Command, args []string) error {
 		RoleNames:    []string{flagRole},
 		ProfileNames: []string{flagProfile},
 		SkipConfirm:  skipConfirm,
+		Concurrency:  concurrency,
 	}
 
-	results, err := processor.ExecString(commandStr)
-	if err != nil {
-		return err
+	results, execErr := processor.ExecStringWithContext(cmd.Context(), commandStr)
+	if execErr != nil && len(results) == 0 {
+		return execErr
 	}
 
 	buffer, err := json.MarshalIndent(results, "", "  ")
@@ -110,5 +133,5 @@ func xargsRunner(cmd *cobra.Command, args []string) error {
 	}
 	fmt.Println(string(buffer))
 
-	return nil
+	return execErr
 }
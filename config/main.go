@@ -0,0 +1,119 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"time"
+
+	"github.com/akerl/voyager/v3/internal/dirs"
+
+	"github.com/akerl/timber/v2/log"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	configName = "voyager"
+	fileName   = "config.yaml"
+
+	// EnvVar overrides the config file path
+	EnvVar = "VOYAGER_CONFIG"
+)
+
+var logger = log.NewLogger("voyager")
+
+// Config holds user defaults for voyager commands
+// Command-line flags take precedence over these settings
+type Config struct {
+	// Prompt is the list prompt type used for interactive selection
+	Prompt string `yaml:"prompt"`
+	// MfaBackends is the order of MFA sources to try, such as yubikey and tty
+	MfaBackends []string `yaml:"mfa_backends"`
+	// Profiles maps partition IDs, such as aws or aws-us-gov, to a preferred source profile
+	Profiles map[string]string `yaml:"profiles"`
+	// Roles picks a default role for accounts matching tag filters, with the first match winning
+	Roles []RoleDefault `yaml:"roles"`
+	// SessionName is a template for the role session name
+	SessionName string `yaml:"session_name"`
+	// KeyringName selects the keyring used to store profile credentials
+	// The keyring settings are defaults which keyring backends in a stores file can override
+	KeyringName string `yaml:"keyring_name"`
+	// StoreCommand is a helper command added to the default store chain ahead of the keyring
+	// It cannot be combined with a stores file, whose chains use exec backends instead
+	StoreCommand []string `yaml:"store_command"`
+	// KeyringAskpass is a command which prints the passphrase for the file keyring
+	KeyringAskpass []string `yaml:"keyring_askpass"`
+	// KeyringUnlockLifetime is how long the file keyring stays unlocked, or -1s to never cache it
+	KeyringUnlockLifetime time.Duration `yaml:"keyring_unlock_lifetime"`
+	// Concurrency is the number of accounts xargs processes at once
+	Concurrency int `yaml:"concurrency"`
+	// Format is the output format for travel
+	Format string `yaml:"format"`
+
+	// Path is the file the config was loaded from
+	Path string `yaml:"-"`
+	// Found is true if the config file existed
+	Found bool `yaml:"-"`
+}
+
+// RoleDefault selects a role for accounts matching a set of tag filters
+type RoleDefault struct {
+	Match []string `yaml:"match"`
+	Role  string   `yaml:"role"`
+}
+
+// Load reads the config file from the path in the environment or the default location
+// A missing file results in an empty Config
+func Load() (*Config, error) {
+	filePath, err := DefaultPath()
+	if err != nil {
+		return nil, err
+	}
+	return LoadFromFile(filePath)
+}
+
+// LoadFromFile reads the config from the provided path
+// A missing file results in an empty Config
+func LoadFromFile(filePath string) (*Config, error) {
+	logger.InfoMsgf("loading config from %s", filePath)
+	c := &Config{Path: filePath}
+	data, err := os.ReadFile(filePath)
+	if errors.Is(err, fs.ErrNotExist) {
+		logger.InfoMsg("no config file found")
+		return c, nil
+	} else if err != nil {
+		return nil, err
+	}
+	c.Found = true
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(c); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("failed to parse %s: %s", filePath, err)
+	}
+	if c.Concurrency < 0 {
+		return nil, fmt.Errorf("concurrency must not be negative: %d", c.Concurrency)
+	}
+	return c, nil
+}
+
+// DefaultPath returns the config file path from the environment,
+// falling back to voyager/config.yaml in the XDG config directory
+func DefaultPath() (string, error) {
+	if filePath := os.Getenv(EnvVar); filePath != "" {
+		return filePath, nil
+	}
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := dirs.Home()
+		if err != nil {
+			return "", err
+		}
+		dir = path.Join(home, ".config")
+	}
+	return path.Join(dir, configName, fileName), nil
+}
@@ -0,0 +1,125 @@
+package console
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/akerl/speculate/v2/creds"
+	"github.com/akerl/timber/v2/log"
+	"github.com/aws/aws-sdk-go/aws/endpoints"
+)
+
+var logger = log.NewLogger("voyager")
+
+const (
+	// MinDuration is the shortest console session the federation endpoint allows
+	MinDuration = 15 * time.Minute
+	// MaxDuration is the longest console session the federation endpoint allows
+	MaxDuration = 12 * time.Hour
+)
+
+var namespaces = map[string]string{
+	"aws":        "aws.amazon",
+	"aws-us-gov": "amazonaws-us-gov",
+}
+
+// signinURL returns the federation endpoint's base URL for a console namespace
+var signinURL = func(namespace string) string {
+	return fmt.Sprintf("https://signin.%s.com", namespace)
+}
+
+type signinTokenResponse struct {
+	SigninToken string
+}
+
+// URL returns a console sign-in URL for the creds, landing on the given service path
+// A non-zero duration is sent as the console session length; otherwise the endpoint's default is used
+func URL(ctx context.Context, c creds.Creds, dest string, duration time.Duration) (string, error) {
+	if duration != 0 && (duration < MinDuration || duration > MaxDuration) {
+		return "", fmt.Errorf("console duration must be between %s and %s", MinDuration, MaxDuration)
+	}
+	namespace, err := namespaceForRegion(c.Region)
+	if err != nil {
+		return "", err
+	}
+	baseURL := signinURL(namespace)
+
+	token, err := signinToken(ctx, c, baseURL, duration)
+	if err != nil {
+		return "", err
+	}
+
+	var targetURL string
+	if c.Region != "" {
+		targetURL = fmt.Sprintf("https://%s.console.%s.com/%s", c.Region, namespace, dest)
+	} else {
+		targetURL = fmt.Sprintf("https://console.%s.com/%s", namespace, dest)
+	}
+	logger.InfoMsgf("using destination url %s", targetURL)
+
+	params := url.Values{}
+	params.Set("Action", "login")
+	params.Set("Issuer", "")
+	params.Set("Destination", targetURL)
+	params.Set("SigninToken", token)
+	return baseURL + "/federation?" + params.Encode(), nil
+}
+
+func signinToken(ctx context.Context, c creds.Creds, baseURL string, duration time.Duration) (string, error) {
+	session, err := json.Marshal(c.Translate(creds.Translations["console"]))
+	if err != nil {
+		return "", err
+	}
+	params := url.Values{}
+	params.Set("Action", "getSigninToken")
+	params.Set("Session", string(session))
+	if duration != 0 {
+		params.Set("SessionDuration", fmt.Sprint(int64(duration.Seconds())))
+	}
+
+	logger.InfoMsg("requesting console signin token")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL+"/federation?"+params.Encode(), nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("signin token request failed: %s", resp.Status)
+	}
+
+	tokenResp := signinTokenResponse{}
+	if err := json.Unmarshal(body, &tokenResp); err != nil {
+		return "", err
+	}
+	if tokenResp.SigninToken == "" {
+		return "", fmt.Errorf("signin token response was empty")
+	}
+	return tokenResp.SigninToken, nil
+}
+
+func namespaceForRegion(region string) (string, error) {
+	partition := endpoints.AwsPartitionID
+	if region != "" {
+		if p, ok := endpoints.PartitionForRegion(endpoints.DefaultPartitions(), region); ok {
+			partition = p.ID()
+		}
+	}
+	namespace, ok := namespaces[partition]
+	if !ok {
+		return "", fmt.Errorf("console is not supported in partition: %s", partition)
+	}
+	return namespace, nil
+}
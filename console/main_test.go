@@ -0,0 +1,89 @@
+package console
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/akerl/speculate/v2/creds"
+)
+
+func fakeSignin(t *testing.T) *url.Values {
+	t.Helper()
+	received := &url.Values{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*received = r.URL.Query()
+		json.NewEncoder(w).Encode(signinTokenResponse{SigninToken: "token"})
+	}))
+	t.Cleanup(server.Close)
+
+	oldSigninURL := signinURL
+	signinURL = func(_ string) string {
+		return server.URL
+	}
+	t.Cleanup(func() {
+		signinURL = oldSigninURL
+	})
+	return received
+}
+
+func TestURLSendsSessionDuration(t *testing.T) {
+	received := fakeSignin(t)
+	c := creds.Creds{AccessKey: "ASIA", SecretKey: "secret", SessionToken: "session", Region: "us-east-1"}
+
+	consoleURL, err := URL(context.Background(), c, "ec2/home", 4*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if received.Get("Action") != "getSigninToken" {
+		t.Errorf("unexpected action: %s", received.Get("Action"))
+	}
+	if received.Get("SessionDuration") != "14400" {
+		t.Errorf("unexpected session duration: %s", received.Get("SessionDuration"))
+	}
+	if !strings.Contains(received.Get("Session"), `"sessionId":"ASIA"`) {
+		t.Errorf("unexpected session: %s", received.Get("Session"))
+	}
+
+	parsed, err := url.Parse(consoleURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := parsed.Query()
+	if query.Get("SigninToken") != "token" {
+		t.Errorf("unexpected signin token: %s", query.Get("SigninToken"))
+	}
+	if query.Get("Destination") != "https://us-east-1.console.aws.amazon.com/ec2/home" {
+		t.Errorf("unexpected destination: %s", query.Get("Destination"))
+	}
+}
+
+func TestURLOmitsDefaultDuration(t *testing.T) {
+	received := fakeSignin(t)
+	c := creds.Creds{AccessKey: "ASIA", SecretKey: "secret", SessionToken: "session", Region: "us-gov-west-1"}
+
+	consoleURL, err := URL(context.Background(), c, "", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := (*received)["SessionDuration"]; ok {
+		t.Errorf("session duration was sent: %s", received.Get("SessionDuration"))
+	}
+	if !strings.Contains(consoleURL, url.QueryEscape("https://us-gov-west-1.console.amazonaws-us-gov.com/")) {
+		t.Errorf("unexpected console url: %s", consoleURL)
+	}
+}
+
+func TestURLRejectsInvalidDuration(t *testing.T) {
+	fakeSignin(t)
+	for _, duration := range []time.Duration{time.Minute, 13 * time.Hour} {
+		if _, err := URL(context.Background(), creds.Creds{}, "", duration); err == nil {
+			t.Errorf("accepted console duration %s", duration)
+		}
+	}
+}
@@ -0,0 +1,203 @@
+package credfile
+
+import (
+	"os"
+	"os/user"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/akerl/timber/v2/log"
+)
+
+const (
+	envVarName = "AWS_SHARED_CREDENTIALS_FILE"
+	dirName    = ".aws"
+	fileName   = "credentials"
+
+	sectionRegexString = `^\s*\[\s*([^\]]+?)\s*\]\s*$`
+	keyRegexString     = `^\s*([^=#;\s]+)\s*=\s*(.*?)\s*$`
+)
+
+var logger = log.NewLogger("voyager")
+
+var sectionRegex = regexp.MustCompile(sectionRegexString)
+var keyRegex = regexp.MustCompile(keyRegexString)
+
+// File is an AWS shared credentials file
+// It is stored line by line so that comments, ordering, and unrelated sections
+// survive being written back to disk
+type File struct {
+	lines []string
+}
+
+// DefaultPath returns the path of the user's shared credentials file
+func DefaultPath() (string, error) {
+	if envPath := os.Getenv(envVarName); envPath != "" {
+		logger.InfoMsgf("using credentials file from env: %s", envPath)
+		return envPath, nil
+	}
+	logger.InfoMsg("looking up home dir")
+	usr, err := user.Current()
+	if err != nil {
+		return "", err
+	}
+	return path.Join(usr.HomeDir, dirName, fileName), nil
+}
+
+// Parse loads a File from raw data
+func Parse(data []byte) *File {
+	text := strings.TrimSuffix(string(data), "\n")
+	if text == "" {
+		return &File{}
+	}
+	return &File{lines: strings.Split(text, "\n")}
+}
+
+// Load reads a File from disk, returning an empty File if it does not exist
+func Load(filePath string) (*File, error) {
+	logger.InfoMsgf("loading credentials file from %s", filePath)
+	data, err := os.ReadFile(filePath)
+	if os.IsNotExist(err) {
+		logger.InfoMsg("credentials file does not exist")
+		return &File{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return Parse(data), nil
+}
+
+// Write saves the File to disk
+func (f *File) Write(filePath string) error {
+	logger.InfoMsgf("writing credentials file to %s", filePath)
+	err := os.MkdirAll(path.Dir(filePath), 0700)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filePath, f.Bytes(), 0600)
+}
+
+// Bytes returns the File's contents
+func (f *File) Bytes() []byte {
+	if len(f.lines) == 0 {
+		return []byte{}
+	}
+	return []byte(strings.Join(f.lines, "\n") + "\n")
+}
+
+// Sections returns the names of all sections in the File
+func (f *File) Sections() []string {
+	res := []string{}
+	for _, line := range f.lines {
+		if match := sectionRegex.FindStringSubmatch(line); match != nil {
+			res = append(res, match[1])
+		}
+	}
+	return res
+}
+
+// Section returns the keys and values from a section, if it exists
+func (f *File) Section(name string) (map[string]string, bool) {
+	start, end := f.bounds(name)
+	if start == -1 {
+		return map[string]string{}, false
+	}
+	res := map[string]string{}
+	for _, line := range f.lines[start+1 : end] {
+		if match := keyRegex.FindStringSubmatch(line); match != nil {
+			res[match[1]] = match[2]
+		}
+	}
+	return res, true
+}
+
+// Set updates keys in a section, creating the section if needed
+// Existing keys are updated in place, and keys with an empty value are removed
+func (f *File) Set(name string, values map[string]string) {
+	logger.InfoMsgf("setting keys in credentials file section: %s", name)
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	start, _ := f.bounds(name)
+	if start == -1 {
+		if len(f.lines) != 0 && strings.TrimSpace(f.lines[len(f.lines)-1]) != "" {
+			f.lines = append(f.lines, "")
+		}
+		f.lines = append(f.lines, "["+name+"]")
+	}
+
+	for _, k := range keys {
+		f.setKey(name, k, values[k])
+	}
+}
+
+// Delete removes a section and all of its keys
+func (f *File) Delete(name string) {
+	logger.InfoMsgf("deleting credentials file section: %s", name)
+	start, end := f.bounds(name)
+	if start == -1 {
+		return
+	}
+	f.lines = append(f.lines[:start], f.lines[end:]...)
+}
+
+func (f *File) setKey(name, key, value string) {
+	start, end := f.bounds(name)
+	insertAt := start + 1
+	for index := start + 1; index < end; index++ {
+		match := keyRegex.FindStringSubmatch(f.lines[index])
+		if match == nil {
+			continue
+		}
+		if match[1] == key {
+			if value == "" {
+				f.lines = append(f.lines[:index], f.lines[index+1:]...)
+			} else {
+				f.lines[index] = key + " = " + value
+			}
+			return
+		}
+		insertAt = index + 1
+	}
+	if value == "" {
+		return
+	}
+	f.lines = append(f.lines[:insertAt], append([]string{key + " = " + value}, f.lines[insertAt:]...)...)
+}
+
+// bounds returns the index of the section header and the index after its last line
+func (f *File) bounds(name string) (int, int) {
+	start := -1
+	for index, line := range f.lines {
+		match := sectionRegex.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		if start != -1 {
+			return start, f.trimTrailing(start, index)
+		}
+		if match[1] == name {
+			start = index
+		}
+	}
+	if start == -1 {
+		return -1, -1
+	}
+	return start, f.trimTrailing(start, len(f.lines))
+}
+
+// trimTrailing excludes blank lines and comments that precede the next section
+func (f *File) trimTrailing(start, end int) int {
+	for end > start+1 {
+		line := strings.TrimSpace(f.lines[end-1])
+		if line != "" && !strings.HasPrefix(line, "#") && !strings.HasPrefix(line, ";") {
+			break
+		}
+		end--
+	}
+	return end
+}
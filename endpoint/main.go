@@ -0,0 +1,119 @@
+package endpoint
+
+import (
+	"encoding/json"
+	"os"
+	"path"
+	"sync"
+
+	"github.com/akerl/voyager/v3/internal/dirs"
+
+	"github.com/akerl/timber/v2/log"
+	"github.com/aws/aws-sdk-go/aws/endpoints"
+)
+
+const (
+	fileName = "endpoints"
+
+	// STSEnvVar overrides the STS endpoint URL for every call
+	STSEnvVar = "VOYAGER_STS_ENDPOINT"
+	// IAMEnvVar overrides the IAM endpoint URL for every call
+	IAMEnvVar = "VOYAGER_IAM_ENDPOINT"
+)
+
+var logger = log.NewLogger("voyager")
+
+var defaultConfig *Config
+var defaultErr error
+var defaultOnce sync.Once
+
+// Settings describe how to reach the STS and IAM APIs
+// URL and IAMURL replace the endpoint entirely, VPCEndpoint sets the hostname
+// of an STS interface endpoint, and FIPS selects FIPS endpoints
+type Settings struct {
+	URL         string `json:"url,omitempty"`
+	IAMURL      string `json:"iam_url,omitempty"`
+	VPCEndpoint string `json:"vpc_endpoint,omitempty"`
+	FIPS        bool   `json:"fips,omitempty"`
+}
+
+// Config holds endpoint settings globally, per partition, and per account
+// More specific settings override less specific ones
+type Config struct {
+	Global     Settings            `json:"global"`
+	Partitions map[string]Settings `json:"partitions,omitempty"`
+	Accounts   map[string]Settings `json:"accounts,omitempty"`
+}
+
+// Default returns the endpoint config from the user's config dir
+// It is loaded once and reused for the life of the process
+func Default() (*Config, error) {
+	defaultOnce.Do(func() {
+		defaultConfig, defaultErr = Load("")
+	})
+	return defaultConfig, defaultErr
+}
+
+// Load reads endpoint config from a file, using the default path if none is given
+// A missing file results in an empty config
+func Load(filePath string) (*Config, error) {
+	config := &Config{}
+	if filePath == "" {
+		dir, err := dirs.Config()
+		if err != nil {
+			return nil, err
+		}
+		filePath = path.Join(dir, fileName)
+	}
+	logger.InfoMsgf("loading endpoint config from %s", filePath)
+	data, err := os.ReadFile(filePath)
+	if os.IsNotExist(err) {
+		logger.InfoMsg("endpoint config does not exist")
+		return config, nil
+	} else if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(data, config)
+	return config, err
+}
+
+// Lookup returns the effective settings for an account in a region
+// The account may be empty for calls not tied to a known account
+func (c *Config) Lookup(account, region string) Settings {
+	var s Settings
+	if c != nil {
+		s = c.Global
+		s = s.merge(c.Partitions[partitionForRegion(region)])
+		if account != "" {
+			s = s.merge(c.Accounts[account])
+		}
+	}
+	return s.merge(Settings{
+		URL:    os.Getenv(STSEnvVar),
+		IAMURL: os.Getenv(IAMEnvVar),
+	})
+}
+
+func (s Settings) merge(other Settings) Settings {
+	if other.URL != "" {
+		s.URL = other.URL
+	}
+	if other.IAMURL != "" {
+		s.IAMURL = other.IAMURL
+	}
+	if other.VPCEndpoint != "" {
+		s.VPCEndpoint = other.VPCEndpoint
+	}
+	if other.FIPS {
+		s.FIPS = true
+	}
+	return s
+}
+
+func partitionForRegion(region string) string {
+	partition, ok := endpoints.PartitionForRegion(endpoints.DefaultPartitions(), region)
+	if !ok {
+		return endpoints.AwsPartitionID
+	}
+	return partition.ID()
+}
@@ -0,0 +1,84 @@
+package endpoint
+
+import (
+	"net/http"
+
+	"github.com/akerl/speculate/v2/creds"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/defaults"
+	"github.com/aws/aws-sdk-go/aws/endpoints"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/sts"
+)
+
+// Apply configures an AWS config to use these settings
+func (s Settings) Apply(config *aws.Config) {
+	config.WithSTSRegionalEndpoint(endpoints.RegionalSTSEndpoint)
+	if s.FIPS {
+		logger.InfoMsg("using fips endpoints")
+		config.WithUseFIPSEndpoint(true)
+	}
+	if s.URL == "" && s.IAMURL == "" && s.VPCEndpoint == "" {
+		return
+	}
+	config.WithEndpointResolver(endpoints.ResolverFunc(s.resolve))
+}
+
+func (s Settings) resolve(service, region string, opts ...func(*endpoints.Options)) (endpoints.ResolvedEndpoint, error) {
+	var url string
+	switch {
+	case service == sts.EndpointsID && s.URL != "":
+		url = s.URL
+	case service == sts.EndpointsID && s.VPCEndpoint != "":
+		url = "https://" + s.VPCEndpoint
+	case service == iam.EndpointsID && s.IAMURL != "":
+		url = s.IAMURL
+	default:
+		return endpoints.DefaultResolver().EndpointFor(service, region, opts...)
+	}
+	logger.InfoMsgf("using custom %s endpoint: %s", service, url)
+	return endpoints.ResolvedEndpoint{
+		URL:           url,
+		SigningRegion: region,
+		SigningName:   service,
+	}, nil
+}
+
+// Session returns an AWS SDK session for the credentials using these settings
+// The session is built explicitly rather than with session.NewSession, so it
+// neither reads nor depends on AWS_* environment variables or shared config files
+func (s Settings) Session(c creds.Creds) (*session.Session, error) {
+	config := aws.NewConfig().
+		WithCredentials(credentials.AnonymousCredentials).
+		WithRegion(c.Region).
+		WithHTTPClient(http.DefaultClient).
+		WithMaxRetries(aws.UseServiceDefaultRetries).
+		WithLogger(aws.NewDefaultLogger()).
+		WithLogLevel(aws.LogOff).
+		WithEndpointResolver(endpoints.DefaultResolver())
+	if c.AccessKey != "" {
+		config.WithCredentials(credentials.NewStaticCredentials(c.AccessKey, c.SecretKey, c.SessionToken))
+	}
+	s.Apply(config)
+	sess := &session.Session{
+		Config:   config,
+		Handlers: defaults.Handlers(),
+	}
+	for _, item := range c.UserAgentItems {
+		sess.Handlers.Build.PushBack(request.MakeAddToUserAgentHandler(item.Name, item.Version, item.Extra...))
+	}
+	return sess, nil
+}
+
+// STSClient returns an STS client for the credentials using these settings
+// Any extra configs are applied to the client on top of the session
+func (s Settings) STSClient(c creds.Creds, configs ...*aws.Config) (*sts.STS, error) {
+	sess, err := s.Session(c)
+	if err != nil {
+		return nil, err
+	}
+	return sts.New(sess, configs...), nil
+}
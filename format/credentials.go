@@ -0,0 +1,54 @@
+package format
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/akerl/voyager/v3/credfile"
+)
+
+// CredentialsFormatter writes the credentials into a section of the AWS shared credentials file
+type CredentialsFormatter struct {
+	Path    string
+	Section string
+}
+
+// Format updates the credentials file and writes a summary comment
+func (f *CredentialsFormatter) Format(w io.Writer, o Output) error {
+	if f.Section == "" {
+		return fmt.Errorf("a section name is required for the credentials format")
+	}
+
+	filePath := f.Path
+	if filePath == "" {
+		var err error
+		filePath, err = credfile.DefaultPath()
+		if err != nil {
+			return err
+		}
+	}
+
+	file, err := credfile.Load(filePath)
+	if err != nil {
+		return err
+	}
+	f.Apply(file, o)
+	if err := file.Write(filePath); err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "# wrote credentials to [%s] in %s\n", f.Section, filePath)
+	if err != nil {
+		return err
+	}
+	return writeConsoleComment(w, o)
+}
+
+// Apply sets the credentials into the configured section of a parsed file
+func (f *CredentialsFormatter) Apply(file *credfile.File, o Output) {
+	file.Set(f.Section, map[string]string{
+		"aws_access_key_id":     o.Creds.AccessKey,
+		"aws_secret_access_key": o.Creds.SecretKey,
+		"aws_session_token":     o.Creds.SessionToken,
+	})
+}
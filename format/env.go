@@ -0,0 +1,67 @@
+package format
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/akerl/speculate/v2/creds"
+)
+
+// ExportFormatter prints export statements for bash and zsh
+type ExportFormatter struct{}
+
+// Format writes the credentials as export statements
+func (f *ExportFormatter) Format(w io.Writer, o Output) error {
+	return writeEnvVars(w, o, "export %s=%s\n")
+}
+
+// FishFormatter prints set statements for the fish shell
+type FishFormatter struct{}
+
+// Format writes the credentials as fish set statements
+func (f *FishFormatter) Format(w io.Writer, o Output) error {
+	return writeEnvVars(w, o, "set -gx %s '%s'\n")
+}
+
+// PowershellFormatter prints env assignments for Windows Powershell
+type PowershellFormatter struct{}
+
+// Format writes the credentials as Powershell env assignments
+func (f *PowershellFormatter) Format(w io.Writer, o Output) error {
+	return writeEnvVars(w, o, "$env:%s = \"%s\"\n")
+}
+
+// DotenvFormatter prints KEY=value lines for .env files
+type DotenvFormatter struct{}
+
+// Format writes the credentials as dotenv lines
+func (f *DotenvFormatter) Format(w io.Writer, o Output) error {
+	return writeEnvVars(w, o, "%s=%s\n")
+}
+
+func writeEnvVars(w io.Writer, o Output, fmtStr string) error {
+	envCreds := o.Creds.Translate(creds.Translations["envvar"])
+	keys := []string{}
+	for k, v := range envCreds {
+		if v != "" {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		if _, err := fmt.Fprintf(w, fmtStr, k, envCreds[k]); err != nil {
+			return err
+		}
+	}
+	return writeConsoleComment(w, o)
+}
+
+func writeConsoleComment(w io.Writer, o Output) error {
+	if o.ConsoleURL == "" {
+		return nil
+	}
+	_, err := fmt.Fprintf(w, "# %s\n", o.ConsoleURL)
+	return err
+}
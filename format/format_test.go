@@ -0,0 +1,154 @@
+package format
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/akerl/voyager/v3/travel"
+
+	"github.com/akerl/speculate/v2/creds"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+func testOutput() Output {
+	return Output{
+		Creds: travel.Creds{
+			Creds: creds.Creds{
+				AccessKey:    "ASIAEXAMPLE",
+				SecretKey:    "secret/key+example",
+				SessionToken: "session-token-example",
+				Region:       "us-east-1",
+			},
+			Expiration: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		},
+		ConsoleURL: "https://signin.aws.amazon.com/federation?Action=login",
+	}
+}
+
+func checkGolden(t *testing.T, name string, actual []byte) {
+	t.Helper()
+	goldenPath := filepath.Join("testdata", name+".golden")
+	if *update {
+		if err := os.WriteFile(goldenPath, actual, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expected, err := os.ReadFile(goldenPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(actual, expected) {
+		t.Errorf("output does not match %s\ngot:\n%s\nwant:\n%s", goldenPath, actual, expected)
+	}
+}
+
+func TestFormatters(t *testing.T) {
+	for _, name := range []string{"export", "fish", "powershell", "dotenv", "json", "credential-process"} {
+		t.Run(name, func(t *testing.T) {
+			formatter, err := New(name, Options{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var buf bytes.Buffer
+			if err := formatter.Format(&buf, testOutput()); err != nil {
+				t.Fatal(err)
+			}
+			checkGolden(t, name, buf.Bytes())
+		})
+	}
+}
+
+func TestShellAliases(t *testing.T) {
+	expected, err := New("export", Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"bash", "zsh"} {
+		formatter, err := New(name, Options{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := formatter.(*ExportFormatter); !ok {
+			t.Errorf("%s returned %T, want %T", name, formatter, expected)
+		}
+	}
+}
+
+func TestStaticCreds(t *testing.T) {
+	o := testOutput()
+	o.Creds.SessionToken = ""
+	o.Creds.Expiration = time.Time{}
+	o.ConsoleURL = ""
+
+	for _, name := range []string{"json", "credential-process"} {
+		t.Run(name, func(t *testing.T) {
+			formatter, err := New(name, Options{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var buf bytes.Buffer
+			if err := formatter.Format(&buf, o); err != nil {
+				t.Fatal(err)
+			}
+			checkGolden(t, name+"-static", buf.Bytes())
+		})
+	}
+}
+
+func TestCredentialsFormatter(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "credentials")
+	existing := "[default]\naws_access_key_id = AKIADEFAULT\naws_secret_access_key = default\n"
+	if err := os.WriteFile(filePath, []byte(existing), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	formatter, err := New("credentials", Options{Path: filePath, Section: "voyager"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := formatter.Format(&buf, testOutput()); err != nil {
+		t.Fatal(err)
+	}
+	checkGolden(t, "credentials", []byte(strings.ReplaceAll(buf.String(), filePath, "CREDENTIALS_FILE")))
+
+	written, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkGolden(t, "credentials-file", written)
+}
+
+func TestCredentialsFormatterRequiresSection(t *testing.T) {
+	formatter := &CredentialsFormatter{Path: filepath.Join(t.TempDir(), "credentials")}
+	if err := formatter.Format(&bytes.Buffer{}, testOutput()); err == nil {
+		t.Error("expected error for missing section")
+	}
+}
+
+func TestDefaultFormatter(t *testing.T) {
+	formatter, err := New("", Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected, err := New(DefaultName(), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprintf("%T", formatter) != fmt.Sprintf("%T", expected) {
+		t.Errorf("default formatter is %T, want %T", formatter, expected)
+	}
+}
+
+func TestUnknownFormatter(t *testing.T) {
+	if _, err := New("nope", Options{}); err == nil {
+		t.Error("expected error for unknown format")
+	}
+}
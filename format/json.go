@@ -0,0 +1,41 @@
+package format
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// JSONFormatter prints the credentials as a JSON object
+type JSONFormatter struct{}
+
+type jsonOutput struct {
+	AccessKeyID     string     `json:"AccessKeyId"`
+	SecretAccessKey string     `json:"SecretAccessKey"`
+	SessionToken    string     `json:"SessionToken,omitempty"`
+	Region          string     `json:"Region,omitempty"`
+	Expiration      *time.Time `json:"Expiration,omitempty"`
+	ConsoleURL      string     `json:"ConsoleURL,omitempty"`
+}
+
+// Format writes the credentials as JSON
+func (f *JSONFormatter) Format(w io.Writer, o Output) error {
+	jo := jsonOutput{
+		AccessKeyID:     o.Creds.AccessKey,
+		SecretAccessKey: o.Creds.SecretKey,
+		SessionToken:    o.Creds.SessionToken,
+		Region:          o.Creds.Region,
+		ConsoleURL:      o.ConsoleURL,
+	}
+	if !o.Creds.Expiration.IsZero() {
+		expiration := o.Creds.Expiration.UTC()
+		jo.Expiration = &expiration
+	}
+	buffer, err := json.MarshalIndent(jo, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(buffer))
+	return err
+}
@@ -0,0 +1,93 @@
+package format
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"sort"
+
+	"github.com/akerl/voyager/v3/travel"
+
+	"github.com/akerl/timber/v2/log"
+)
+
+var logger = log.NewLogger("voyager")
+
+// Output defines the information available to a Formatter
+type Output struct {
+	Creds      travel.Creds
+	ConsoleURL string
+}
+
+// Options defines settings used by Formatters which write to files
+type Options struct {
+	Path    string
+	Section string
+}
+
+// Formatter renders credentials for consumption by another tool
+type Formatter interface {
+	Format(io.Writer, Output) error
+}
+
+// DefaultName returns the format used when none is requested
+func DefaultName() string {
+	if runtime.GOOS == "windows" {
+		return "powershell"
+	}
+	return "export"
+}
+
+// Types provides a map of formatters by name
+var Types = map[string]func(Options) Formatter{
+	"export": func(_ Options) Formatter {
+		return &ExportFormatter{}
+	},
+	"bash": func(_ Options) Formatter {
+		return &ExportFormatter{}
+	},
+	"zsh": func(_ Options) Formatter {
+		return &ExportFormatter{}
+	},
+	"fish": func(_ Options) Formatter {
+		return &FishFormatter{}
+	},
+	"powershell": func(_ Options) Formatter {
+		return &PowershellFormatter{}
+	},
+	"dotenv": func(_ Options) Formatter {
+		return &DotenvFormatter{}
+	},
+	"json": func(_ Options) Formatter {
+		return &JSONFormatter{}
+	},
+	"credential-process": func(_ Options) Formatter {
+		return &ProcessFormatter{}
+	},
+	"credentials": func(o Options) Formatter {
+		return &CredentialsFormatter{Path: o.Path, Section: o.Section}
+	},
+}
+
+// New returns a Formatter by name, using DefaultName if name is empty
+func New(name string, opts Options) (Formatter, error) {
+	if name == "" {
+		name = DefaultName()
+	}
+	logger.InfoMsgf("looking up formatter: %s", name)
+	generator, ok := Types[name]
+	if !ok {
+		return nil, fmt.Errorf("format type not found: %s", name)
+	}
+	return generator(opts), nil
+}
+
+// Names returns the list of valid format names
+func Names() []string {
+	res := []string{}
+	for k := range Types {
+		res = append(res, k)
+	}
+	sort.Strings(res)
+	return res
+}
@@ -0,0 +1,41 @@
+package format
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// ProcessFormatter prints credentials for the AWS SDK's credential_process setting
+// The structure is defined at
+// https://docs.aws.amazon.com/sdkref/latest/guide/feature-process-credentials.html
+type ProcessFormatter struct{}
+
+type processOutput struct {
+	Version         int        `json:"Version"`
+	AccessKeyID     string     `json:"AccessKeyId"`
+	SecretAccessKey string     `json:"SecretAccessKey"`
+	SessionToken    string     `json:"SessionToken,omitempty"`
+	Expiration      *time.Time `json:"Expiration,omitempty"`
+}
+
+// Format writes the credentials as versioned credential_process JSON
+func (f *ProcessFormatter) Format(w io.Writer, o Output) error {
+	po := processOutput{
+		Version:         1,
+		AccessKeyID:     o.Creds.AccessKey,
+		SecretAccessKey: o.Creds.SecretKey,
+		SessionToken:    o.Creds.SessionToken,
+	}
+	if !o.Creds.Expiration.IsZero() {
+		expiration := o.Creds.Expiration.UTC()
+		po.Expiration = &expiration
+	}
+	buffer, err := json.Marshal(po)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(buffer))
+	return err
+}
@@ -16,6 +16,9 @@ require (
 	github.com/spf13/cobra v1.8.0
 	github.com/vbauerster/mpb/v4 v4.12.2
 	github.com/yawn/ykoath v1.0.5
+	golang.org/x/sys v0.14.0
+	golang.org/x/term v0.13.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -47,8 +50,6 @@ require (
 	github.com/rivo/uniseg v0.4.3 // indirect
 	github.com/spf13/pflag v1.0.5 // indirect
 	golang.org/x/crypto v0.0.0-20210921155107-089bfa567519 // indirect
-	golang.org/x/sys v0.14.0 // indirect
-	golang.org/x/term v0.13.0 // indirect
 	golang.org/x/text v0.13.0 // indirect
 	rsc.io/qr v0.2.0 // indirect
 )
@@ -0,0 +1,178 @@
+package history
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/akerl/voyager/v3/internal/dirs"
+	"github.com/akerl/voyager/v3/internal/lockedfile"
+
+	"github.com/akerl/timber/v2/log"
+)
+
+const (
+	fileName = "history"
+
+	// EnvVar overrides the history file path, or disables history when set to "off"
+	EnvVar = "VOYAGER_HISTORY"
+
+	// MaxEntries is the number of entries kept in the history file
+	MaxEntries = 200
+)
+
+var logger = log.NewLogger("voyager")
+
+// Entry records a successful path resolution
+type Entry struct {
+	Time    time.Time `json:"time"`
+	Account string    `json:"account"`
+	Role    string    `json:"role"`
+	Profile string    `json:"profile"`
+}
+
+// Target returns a human-readable description of the entry's destination
+func (e Entry) Target() string {
+	return fmt.Sprintf("%s/%s (via %s)", e.Account, e.Role, e.Profile)
+}
+
+func (e Entry) sameTarget(other Entry) bool {
+	return e.Account == other.Account && e.Role == other.Role && e.Profile == other.Profile
+}
+
+// History is a list of entries, oldest first
+type History struct {
+	Entries []Entry
+	Path    string
+}
+
+// Load reads the history file
+// A missing file results in an empty History
+func Load() (*History, error) {
+	h := &History{}
+	filePath, err := h.getPath()
+	if err != nil || filePath == "" {
+		return h, err
+	}
+	h.Entries, err = readEntries(filePath)
+	return h, err
+}
+
+func readEntries(filePath string) ([]Entry, error) {
+	data, err := os.ReadFile(filePath)
+	if errors.Is(err, fs.ErrNotExist) {
+		logger.InfoMsg("no history file found")
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	entries := []Entry{}
+	err = json.Unmarshal(data, &entries)
+	return entries, err
+}
+
+// Add appends an entry and writes the history file, keeping the newest MaxEntries
+// The file is re-read under a lock first, so entries added by concurrent processes are kept
+func (h *History) Add(e Entry) error {
+	if e.Time.IsZero() {
+		e.Time = time.Now().UTC()
+	}
+	filePath, err := h.getPath()
+	if err != nil || filePath == "" {
+		h.append(e)
+		return err
+	}
+	return lockedfile.WithLock(filePath, func() error {
+		entries, err := readEntries(filePath)
+		if err != nil {
+			return err
+		}
+		h.Entries = entries
+		h.append(e)
+		data, err := json.MarshalIndent(h.Entries, "", "  ")
+		if err != nil {
+			return err
+		}
+		logger.InfoMsgf("writing %d history entries to %s", len(h.Entries), filePath)
+		return lockedfile.WriteFile(filePath, data, 0600)
+	})
+}
+
+func (h *History) append(e Entry) {
+	h.Entries = append(h.Entries, e)
+	if len(h.Entries) > MaxEntries {
+		h.Entries = h.Entries[len(h.Entries)-MaxEntries:]
+	}
+}
+
+// Last returns the most recent entry
+func (h *History) Last() (Entry, bool) {
+	if len(h.Entries) == 0 {
+		return Entry{}, false
+	}
+	return h.Entries[len(h.Entries)-1], true
+}
+
+// Recent returns up to count entries, newest first, with repeat targets removed
+// A count of 0 returns all unique targets
+func (h *History) Recent(count int) []Entry {
+	res := []Entry{}
+	for i := len(h.Entries) - 1; i >= 0; i-- {
+		if count != 0 && len(res) == count {
+			break
+		}
+		seen := false
+		for _, item := range res {
+			if item.sameTarget(h.Entries[i]) {
+				seen = true
+				break
+			}
+		}
+		if !seen {
+			res = append(res, h.Entries[i])
+		}
+	}
+	return res
+}
+
+// Accounts returns the account IDs from the history, most recently used first
+func (h *History) Accounts() []string {
+	res := []string{}
+	seen := map[string]bool{}
+	for i := len(h.Entries) - 1; i >= 0; i-- {
+		account := h.Entries[i].Account
+		if !seen[account] {
+			seen[account] = true
+			res = append(res, account)
+		}
+	}
+	return res
+}
+
+// getPath returns the history file path, or an empty string if history is disabled
+func (h *History) getPath() (string, error) {
+	if h.Path != "" {
+		return h.Path, nil
+	}
+	setting := os.Getenv(EnvVar)
+	switch strings.ToLower(setting) {
+	case "off", "none":
+		logger.InfoMsg("history disabled")
+		return "", nil
+	case "":
+		dir, err := dirs.Config()
+		if err != nil {
+			return "", err
+		}
+		h.Path = path.Join(dir, fileName)
+	default:
+		h.Path = setting
+	}
+	logger.InfoMsgf("set history path: %s", h.Path)
+	return h.Path, nil
+}
@@ -0,0 +1,54 @@
+package history
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestConcurrentAddsKeepAllEntries(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), fileName)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			h := &History{Path: filePath}
+			if err := h.Add(Entry{Account: fmt.Sprintf("%012d", i), Role: "admin"}); err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	h := &History{Path: filePath}
+	entries, err := readEntries(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.Entries = entries
+	if accounts := h.Accounts(); len(accounts) != 20 {
+		t.Errorf("lost history entries: %d of 20 kept", len(accounts))
+	}
+}
+
+func TestAddKeepsNewestEntries(t *testing.T) {
+	h := &History{Path: filepath.Join(t.TempDir(), fileName)}
+	for i := 0; i < MaxEntries+5; i++ {
+		if err := h.Add(Entry{Account: fmt.Sprintf("%012d", i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	entries, err := readEntries(h.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != MaxEntries {
+		t.Fatalf("kept %d entries", len(entries))
+	}
+	if last, _ := h.Last(); last.Account != fmt.Sprintf("%012d", MaxEntries+4) {
+		t.Errorf("unexpected last entry: %+v", last)
+	}
+}
@@ -0,0 +1,41 @@
+// Package dirs locates voyager's files in the current user's home directory
+package dirs
+
+import (
+	"os"
+	"os/user"
+	"path"
+
+	"github.com/akerl/timber/v2/log"
+)
+
+const (
+	configName = ".voyager"
+)
+
+var logger = log.NewLogger("voyager")
+
+// Config returns voyager's config directory, creating it if needed
+func Config() (string, error) {
+	logger.InfoMsg("looking up config dir")
+	home, err := Home()
+	if err != nil {
+		return "", err
+	}
+	dir := path.Join(home, configName)
+	err = os.MkdirAll(dir, 0700)
+	if err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+// Home returns the current user's home directory
+func Home() (string, error) {
+	logger.InfoMsg("looking up home dir")
+	usr, err := user.Current()
+	if err != nil {
+		return "", err
+	}
+	return usr.HomeDir, nil
+}
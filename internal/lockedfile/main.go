@@ -0,0 +1,49 @@
+// Package lockedfile updates files shared by concurrent voyager processes
+package lockedfile
+
+import (
+	"os"
+	"path"
+)
+
+const (
+	lockSuffix = ".lock"
+	tmpSuffix  = ".tmp"
+)
+
+// WithLock runs fn while holding an exclusive lock on a sidecar lock file next to filePath
+// Callers should re-read the file inside fn, since another process may have changed it
+func WithLock(filePath string, fn func() error) error {
+	lock, err := os.OpenFile(filePath+lockSuffix, os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return err
+	}
+	defer lock.Close()
+	if err := lockFile(lock); err != nil {
+		return err
+	}
+	defer unlockFile(lock)
+	return fn()
+}
+
+// WriteFile replaces filePath by writing a temporary file beside it and renaming it into place,
+// so readers never see a partially written file
+func WriteFile(filePath string, data []byte, perm os.FileMode) error {
+	tmpFile, err := os.CreateTemp(path.Dir(filePath), path.Base(filePath)+tmpSuffix)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpFile.Name())
+	if _, err := tmpFile.Write(data); err != nil {
+		tmpFile.Close()
+		return err
+	}
+	if err := tmpFile.Chmod(perm); err != nil {
+		tmpFile.Close()
+		return err
+	}
+	if err := tmpFile.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmpFile.Name(), filePath)
+}
@@ -0,0 +1,70 @@
+package lockedfile
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestWithLockSerializesUpdates(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "counter")
+	if err := WriteFile(filePath, []byte("0"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := WithLock(filePath, func() error {
+				data, err := os.ReadFile(filePath)
+				if err != nil {
+					return err
+				}
+				count, err := strconv.Atoi(string(data))
+				if err != nil {
+					return err
+				}
+				return WriteFile(filePath, []byte(fmt.Sprint(count+1)), 0600)
+			})
+			if err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "20" {
+		t.Errorf("lost updates: counter is %s", data)
+	}
+}
+
+func TestWriteFileSetsMode(t *testing.T) {
+	dir := t.TempDir()
+	filePath := filepath.Join(dir, "data")
+	if err := WriteFile(filePath, []byte("data"), 0640); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0640 {
+		t.Errorf("file has mode %#o", info.Mode().Perm())
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("temporary files were left behind: %v", entries)
+	}
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"os"
 
 	"github.com/akerl/voyager/v3/cmd"
@@ -10,6 +11,10 @@ import (
 
 func main() {
 	if err := cmd.Execute(); err != nil {
+		var exitErr cmd.ExitError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.Code)
+		}
 		helpers.PrintAwsError(err)
 		os.Exit(1)
 	}
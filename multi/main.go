@@ -2,8 +2,11 @@ package multi
 
 import (
 	"bufio"
+	"bytes"
+	"context"
 	"fmt"
 	"os"
+	"os/exec"
 	"strings"
 	"time"
 
@@ -18,6 +21,9 @@ import (
 
 var logger = log.NewLogger("voyager")
 
+// DefaultConcurrency is the number of accounts processed at once when unset
+const DefaultConcurrency = 10
+
 // Processor defines the settings for parallel processing
 type Processor struct {
 	Grapher      travel.Grapher
@@ -27,6 +33,7 @@ type Processor struct {
 	ProfileNames []string
 	SkipConfirm  bool
 	KeyFunc      func(cartogram.Account) (string, cartogram.Tags)
+	Concurrency  int
 }
 
 // ExecResult is based on creds.ExecResult but adds account tags
@@ -36,22 +43,35 @@ type ExecResult struct {
 	ExitCode int            `json:"exitcode"`
 	StdOut   string         `json:"stdout"`
 	StdErr   string         `json:"stderr"`
+	Retries  int            `json:"retries"`
 }
 
 // ExecString runs a command string against a set of accounts
 func (p Processor) ExecString(cmd string) (map[string]ExecResult, error) {
+	return p.ExecStringWithContext(context.Background(), cmd)
+}
+
+// ExecStringWithContext runs a command string against a set of accounts until the context is cancelled
+func (p Processor) ExecStringWithContext(ctx context.Context, cmd string) (map[string]ExecResult, error) {
 	args, err := creds.StringToCommand(cmd)
 	if err != nil {
 		return map[string]ExecResult{}, err
 	}
-	return p.Exec(args)
+	return p.ExecWithContext(ctx, args)
 }
 
 // Exec runs a command against a set of accounts
 func (p Processor) Exec(cmd []string) (map[string]ExecResult, error) {
+	return p.ExecWithContext(context.Background(), cmd)
+}
+
+// ExecWithContext runs a command against a set of accounts until the context is cancelled
+// On cancellation, running commands are killed, accounts that were not started are skipped,
+// and the results gathered so far are returned along with the context's error
+func (p Processor) ExecWithContext(ctx context.Context, cmd []string) (map[string]ExecResult, error) {
 	logger.InfoMsgf("processing command: %v", cmd)
 
-	paths, err := p.Grapher.ResolveAll(p.Args, p.RoleNames, p.ProfileNames)
+	paths, err := p.Grapher.ResolveAllWithContext(ctx, p.Args, p.RoleNames, p.ProfileNames)
 	if err != nil {
 		return map[string]ExecResult{}, err
 	}
@@ -64,8 +84,8 @@ func (p Processor) Exec(cmd []string) (map[string]ExecResult, error) {
 	outputCh := make(chan workerOutput, len(paths))
 	refreshCh := make(chan time.Time)
 
-	for i := 1; i <= 10; i++ {
-		go execWorker(inputCh, outputCh)
+	for i := 1; i <= p.getConcurrency(); i++ {
+		go execWorker(ctx, inputCh, outputCh)
 	}
 
 	for _, item := range paths {
@@ -99,13 +119,22 @@ func (p Processor) Exec(cmd []string) (map[string]ExecResult, error) {
 	output := map[string]ExecResult{}
 	for i := 1; i <= len(paths); i++ {
 		result := <-outputCh
-		output[result.Key] = result.ExecResult
+		if !result.Skipped {
+			output[result.Key] = result.ExecResult
+		}
 		bar.Increment()
 		refreshCh <- time.Now()
 	}
 	progress.Wait()
 
-	return output, nil
+	return output, ctx.Err()
+}
+
+func (p Processor) getConcurrency() int {
+	if p.Concurrency <= 0 {
+		return DefaultConcurrency
+	}
+	return p.Concurrency
 }
 
 // ParseKey derives an output key from an account
@@ -160,16 +189,35 @@ type workerInput struct {
 type workerOutput struct {
 	Key        string
 	ExecResult ExecResult
+	Skipped    bool
 }
 
-func execWorker(inputCh <-chan workerInput, outputCh chan<- workerOutput) {
+func execWorker(ctx context.Context, inputCh <-chan workerInput, outputCh chan<- workerOutput) {
 	for item := range inputCh {
-		c, err := item.Path.TraverseWithOptions(item.Options)
+		if ctx.Err() != nil {
+			outputCh <- workerOutput{Key: item.Key, Skipped: true}
+			continue
+		}
+
+		retries := 0
+		opts := item.Options
+		onRetry := opts.Retry.OnRetry
+		opts.Retry.OnRetry = func(h travel.Hop, attempt int, err error) {
+			retries++
+			if onRetry != nil {
+				onRetry(h, attempt, err)
+			}
+		}
+
+		c, err := item.Path.TraverseWithContext(ctx, opts)
 		if err != nil {
-			outputCh <- workerOutput{ExecResult: ExecResult{Error: err}}
+			outputCh <- workerOutput{
+				Key:        item.Key,
+				ExecResult: ExecResult{Tags: item.Tags, Error: err, Retries: retries},
+			}
 			continue
 		}
-		result := c.Exec(item.Command)
+		result := execWithContext(ctx, c, item.Command)
 		outputCh <- workerOutput{
 			Key: item.Key,
 			ExecResult: ExecResult{
@@ -178,7 +226,39 @@ func execWorker(inputCh <-chan workerInput, outputCh chan<- workerOutput) {
 				ExitCode: result.ExitCode,
 				StdOut:   result.StdOut,
 				StdErr:   result.StdErr,
+				Retries:  retries,
 			},
 		}
 	}
 }
+
+// execWithContext mirrors creds.Exec, killing the command if the context is cancelled
+func execWithContext(ctx context.Context, c travel.Creds, command []string) creds.ExecResult {
+	logger.InfoMsgf("executing command: %v", command)
+
+	cmd := exec.CommandContext(ctx, command[0], command[1:]...)
+	cmd.Env = c.ToEnviron()
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	exitCode := 0
+	err := cmd.Run()
+	if err != nil {
+		exitCode = -1
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			exitCode = exitErr.ExitCode()
+		}
+	}
+	if ctxErr := ctx.Err(); ctxErr != nil && err != nil {
+		err = ctxErr
+	}
+
+	return creds.ExecResult{
+		Error:    err,
+		ExitCode: exitCode,
+		StdOut:   stdout.String(),
+		StdErr:   stderr.String(),
+	}
+}
@@ -0,0 +1,185 @@
+package profiles
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"time"
+
+	"github.com/akerl/voyager/v3/internal/dirs"
+)
+
+const (
+	storesFileName  = "stores"
+	defaultChain    = "default"
+	maxChainNesting = 8
+)
+
+// StoreConfig defines named chains of storage backends
+// Path is the file the chains were loaded from, and is empty for DefaultStoreConfig
+type StoreConfig struct {
+	Default string                 `json:"default,omitempty"`
+	Chains  map[string]ChainConfig `json:"chains"`
+	Path    string                 `json:"-"`
+}
+
+// ChainConfig defines a MultiStore
+// WriteForward defaults to true, saving credentials found in a later backend to the
+// first writable one
+type ChainConfig struct {
+	WriteForward *bool           `json:"write_forward,omitempty"`
+	Backends     []BackendConfig `json:"backends"`
+}
+
+// BackendConfig defines a single storage backend
+// Type is one of keyring, env, prompt, exec, aws-credentials, or chain
+type BackendConfig struct {
+	Type           string   `json:"type"`
+	Name           string   `json:"name,omitempty"`
+	Askpass        []string `json:"askpass,omitempty"`
+	UnlockLifetime string   `json:"unlock_lifetime,omitempty"`
+	Prefix         string   `json:"prefix,omitempty"`
+	Command        []string `json:"command,omitempty"`
+	Path           string   `json:"path,omitempty"`
+	Chain          string   `json:"chain,omitempty"`
+}
+
+// backendTypes builds backends by type; keyring backends start from the provided defaults
+// The chain type is handled by StoreConfig, since it refers to other chains
+var backendTypes = map[string]func(BackendConfig, KeyringStore) (Store, error){
+	"keyring": func(b BackendConfig, k KeyringStore) (Store, error) {
+		if b.Name != "" {
+			k.Name = b.Name
+		}
+		if len(b.Askpass) != 0 {
+			k.Askpass = b.Askpass
+		}
+		if b.UnlockLifetime != "" {
+			lifetime, err := time.ParseDuration(b.UnlockLifetime)
+			if err != nil {
+				return nil, err
+			}
+			k.UnlockLifetime = lifetime
+		}
+		return &k, nil
+	},
+	"env": func(b BackendConfig, _ KeyringStore) (Store, error) {
+		return &EnvStore{Prefix: b.Prefix}, nil
+	},
+	"prompt": func(_ BackendConfig, _ KeyringStore) (Store, error) {
+		return &PromptStore{}, nil
+	},
+	"exec": func(b BackendConfig, _ KeyringStore) (Store, error) {
+		if len(b.Command) == 0 {
+			return nil, fmt.Errorf("exec backend requires a command")
+		}
+		return &ExecStore{Command: b.Command}, nil
+	},
+	"aws-credentials": func(b BackendConfig, _ KeyringStore) (Store, error) {
+		return &CredentialsFileStore{Path: b.Path}, nil
+	},
+}
+
+// DefaultStoreConfig returns the chain used when no stores file exists
+func DefaultStoreConfig() *StoreConfig {
+	return &StoreConfig{
+		Default: defaultChain,
+		Chains: map[string]ChainConfig{
+			defaultChain: {
+				Backends: []BackendConfig{
+					{Type: "keyring"},
+					{Type: "prompt"},
+				},
+			},
+		},
+	}
+}
+
+// DefaultStoreConfigWithCommand returns the default chain with an exec backend for the
+// helper command ahead of the keyring
+func DefaultStoreConfigWithCommand(command []string) *StoreConfig {
+	sc := DefaultStoreConfig()
+	chain := sc.Chains[defaultChain]
+	chain.Backends = append([]BackendConfig{{Type: "exec", Command: command}}, chain.Backends...)
+	sc.Chains[defaultChain] = chain
+	return sc
+}
+
+// LoadStoreConfig reads chains from a file, using the default path if none is given
+// A missing file results in DefaultStoreConfig
+func LoadStoreConfig(filePath string) (*StoreConfig, error) {
+	if filePath == "" {
+		dir, err := dirs.Config()
+		if err != nil {
+			return nil, err
+		}
+		filePath = path.Join(dir, storesFileName)
+	}
+	logger.InfoMsgf("loading store config from %s", filePath)
+	data, err := os.ReadFile(filePath)
+	if os.IsNotExist(err) {
+		logger.InfoMsg("store config does not exist")
+		return DefaultStoreConfig(), nil
+	} else if err != nil {
+		return nil, err
+	}
+	sc := &StoreConfig{Path: filePath}
+	if err := json.Unmarshal(data, sc); err != nil {
+		return nil, err
+	}
+	if len(sc.Chains) == 0 {
+		return nil, fmt.Errorf("no store chains defined in %s", filePath)
+	}
+	return sc, nil
+}
+
+// Store builds the named chain, or the default chain if name is empty
+// Keyring backends use the provided KeyringStore for any settings they do not set
+func (sc *StoreConfig) Store(name string, keyring KeyringStore) (Store, error) {
+	return sc.build(name, keyring, 0)
+}
+
+// DefaultName returns the chain used when none is requested
+func (sc *StoreConfig) DefaultName() string {
+	if sc.Default != "" {
+		return sc.Default
+	}
+	return defaultChain
+}
+
+func (sc *StoreConfig) build(name string, keyring KeyringStore, depth int) (Store, error) {
+	if depth > maxChainNesting {
+		return nil, fmt.Errorf("store chains are nested too deeply")
+	}
+	if name == "" {
+		name = sc.DefaultName()
+	}
+	chain, ok := sc.Chains[name]
+	if !ok {
+		return nil, fmt.Errorf("store chain not found: %s", name)
+	}
+	logger.InfoMsgf("building store chain: %s", name)
+
+	m := &MultiStore{NoWriteForward: chain.WriteForward != nil && !*chain.WriteForward}
+	for _, item := range chain.Backends {
+		if item.Type == "chain" {
+			backend, err := sc.build(item.Chain, keyring, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			m.Backends = append(m.Backends, backend)
+			continue
+		}
+		generator, ok := backendTypes[item.Type]
+		if !ok {
+			return nil, fmt.Errorf("store backend type not found: %s", item.Type)
+		}
+		backend, err := generator(item, keyring)
+		if err != nil {
+			return nil, fmt.Errorf("store chain %s: %s", name, err)
+		}
+		m.Backends = append(m.Backends, backend)
+	}
+	return m, nil
+}
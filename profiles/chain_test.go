@@ -0,0 +1,24 @@
+package profiles
+
+import (
+	"testing"
+)
+
+func TestDefaultStoreConfigWithCommand(t *testing.T) {
+	sc := DefaultStoreConfigWithCommand([]string{"helper"})
+	store, err := sc.Store("", KeyringStore{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := store.(*MultiStore)
+	if len(m.Backends) != 3 {
+		t.Fatalf("expected exec, keyring, and prompt backends, got %d", len(m.Backends))
+	}
+	if e, ok := m.Backends[0].(*ExecStore); !ok || e.Command[0] != "helper" {
+		t.Errorf("expected exec backend first, got %T", m.Backends[0])
+	}
+
+	if len(DefaultStoreConfig().Chains[defaultChain].Backends) != 2 {
+		t.Error("adding a command changed the shared default config")
+	}
+}
@@ -0,0 +1,127 @@
+package profiles
+
+import (
+	"fmt"
+
+	"github.com/akerl/voyager/v3/credfile"
+
+	"github.com/aws/aws-sdk-go/aws/credentials"
+)
+
+const (
+	credfileAccessKey = "aws_access_key_id"
+	credfileSecretKey = "aws_secret_access_key"
+)
+
+// CredentialsFileStore is a storage backend using an AWS shared credentials file
+// Sections are read and written in place, so comments and other sections are preserved
+type CredentialsFileStore struct {
+	Path string
+}
+
+// Lookup reads credentials from the profile's section
+func (c *CredentialsFileStore) Lookup(profile string) (credentials.Value, error) {
+	logger.InfoMsgf("looking up %s in credentials file store", profile)
+	file, err := c.load()
+	if err != nil {
+		return credentials.Value{}, err
+	}
+	section, ok := file.Section(profile)
+	if !ok {
+		return credentials.Value{}, fmt.Errorf("profile not found in credentials file: %s", profile)
+	}
+	value := credentials.Value{
+		AccessKeyID:     section[credfileAccessKey],
+		SecretAccessKey: section[credfileSecretKey],
+	}
+	if value.AccessKeyID == "" || value.SecretAccessKey == "" {
+		return credentials.Value{}, fmt.Errorf("profile in credentials file has no keypair: %s", profile)
+	}
+	return value, nil
+}
+
+// Write stores credentials in the profile's section
+func (c *CredentialsFileStore) Write(profile string, creds credentials.Value) error {
+	logger.InfoMsgf("writing %s in credentials file store", profile)
+	file, err := c.load()
+	if err != nil {
+		return err
+	}
+	file.Set(profile, map[string]string{
+		credfileAccessKey: creds.AccessKeyID,
+		credfileSecretKey: creds.SecretAccessKey,
+	})
+	return c.write(file)
+}
+
+// Check returns if the profile's section has a keypair
+func (c *CredentialsFileStore) Check(profile string) bool {
+	logger.InfoMsgf("checking for %s in credentials file store", profile)
+	_, err := c.Lookup(profile)
+	return err == nil
+}
+
+// Delete removes the keypair from the profile's section
+// Other settings in the section are kept, and the section is removed if nothing is left
+func (c *CredentialsFileStore) Delete(profile string) error {
+	logger.InfoMsgf("deleting %s from credentials file store", profile)
+	file, err := c.load()
+	if err != nil {
+		return err
+	}
+	if _, ok := file.Section(profile); !ok {
+		return nil
+	}
+	file.Set(profile, map[string]string{
+		credfileAccessKey: "",
+		credfileSecretKey: "",
+	})
+	if section, _ := file.Section(profile); len(section) == 0 {
+		file.Delete(profile)
+	}
+	return c.write(file)
+}
+
+// List returns the profiles in the file which have a keypair
+func (c *CredentialsFileStore) List() ([]string, error) {
+	file, err := c.load()
+	if err != nil {
+		return []string{}, err
+	}
+	res := []string{}
+	for _, name := range file.Sections() {
+		section, _ := file.Section(name)
+		if section[credfileAccessKey] != "" && section[credfileSecretKey] != "" {
+			res = append(res, name)
+		}
+	}
+	return res, nil
+}
+
+func (c *CredentialsFileStore) load() (*credfile.File, error) {
+	filePath, err := c.getPath()
+	if err != nil {
+		return nil, err
+	}
+	return credfile.Load(filePath)
+}
+
+func (c *CredentialsFileStore) write(file *credfile.File) error {
+	filePath, err := c.getPath()
+	if err != nil {
+		return err
+	}
+	return file.Write(filePath)
+}
+
+func (c *CredentialsFileStore) getPath() (string, error) {
+	if c.Path == "" {
+		filePath, err := credfile.DefaultPath()
+		if err != nil {
+			return "", err
+		}
+		c.Path = filePath
+		logger.InfoMsgf("set credentials file store path to default: %s", c.Path)
+	}
+	return c.Path, nil
+}
@@ -0,0 +1,57 @@
+package profiles
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws/credentials"
+)
+
+// DefaultEnvPrefix is the env var prefix used by EnvStore when none is set
+const DefaultEnvPrefix = "VOYAGER_PROFILE_"
+
+var envNameRegex = regexp.MustCompile(`[^A-Z0-9]+`)
+
+// EnvStore is a storage backend which reads credentials from environment variables
+// A profile named "comm-ops" is read from VOYAGER_PROFILE_COMM_OPS_ACCESS_KEY_ID
+// and VOYAGER_PROFILE_COMM_OPS_SECRET_ACCESS_KEY
+type EnvStore struct {
+	Prefix string
+}
+
+// Lookup reads credentials from the environment
+func (e *EnvStore) Lookup(profile string) (credentials.Value, error) {
+	logger.InfoMsgf("looking up %s in env store", profile)
+	accessKeyVar, secretKeyVar := e.varNames(profile)
+	value := credentials.Value{
+		AccessKeyID:     os.Getenv(accessKeyVar),
+		SecretAccessKey: os.Getenv(secretKeyVar),
+	}
+	if value.AccessKeyID == "" || value.SecretAccessKey == "" {
+		return credentials.Value{}, fmt.Errorf("%s and %s must be set", accessKeyVar, secretKeyVar)
+	}
+	return value, nil
+}
+
+// Check returns if the credentials are set in the environment
+func (e *EnvStore) Check(profile string) bool {
+	logger.InfoMsgf("checking for %s in env store", profile)
+	_, err := e.Lookup(profile)
+	return err == nil
+}
+
+// Delete is a no-op, as the environment belongs to the caller
+func (e *EnvStore) Delete(_ string) error {
+	return nil
+}
+
+func (e *EnvStore) varNames(profile string) (string, string) {
+	prefix := e.Prefix
+	if prefix == "" {
+		prefix = DefaultEnvPrefix
+	}
+	name := strings.Trim(envNameRegex.ReplaceAllString(strings.ToUpper(profile), "_"), "_")
+	return prefix + name + "_ACCESS_KEY_ID", prefix + name + "_SECRET_ACCESS_KEY"
+}
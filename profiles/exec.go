@@ -0,0 +1,129 @@
+package profiles
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws/credentials"
+)
+
+const (
+	execActionGet   = "get"
+	execActionStore = "store"
+	execActionCheck = "check"
+	execActionErase = "erase"
+	execActionList  = "list"
+)
+
+// ExecStore is a storage backend which runs a helper command, such as a password manager wrapper
+// The helper is run with the action (get, store, check, erase, or list) appended to Command,
+// reads a JSON request on stdin, and writes a JSON response to stdout
+// A non-zero exit or an "error" field in the response indicates failure
+type ExecStore struct {
+	Command []string
+}
+
+type execRequest struct {
+	Profile         string `json:"profile,omitempty"`
+	AccessKeyID     string `json:"access_key_id,omitempty"`
+	SecretAccessKey string `json:"secret_access_key,omitempty"`
+}
+
+type execResponse struct {
+	AccessKeyID     string   `json:"access_key_id,omitempty"`
+	SecretAccessKey string   `json:"secret_access_key,omitempty"`
+	Found           bool     `json:"found,omitempty"`
+	Profiles        []string `json:"profiles,omitempty"`
+	Error           string   `json:"error,omitempty"`
+}
+
+// Lookup asks the helper for credentials
+func (e *ExecStore) Lookup(profile string) (credentials.Value, error) {
+	logger.InfoMsgf("looking up %s in exec store", profile)
+	resp, err := e.run(execActionGet, execRequest{Profile: profile})
+	if err != nil {
+		return credentials.Value{}, err
+	}
+	if resp.AccessKeyID == "" || resp.SecretAccessKey == "" {
+		return credentials.Value{}, fmt.Errorf("exec store returned no credentials for %s", profile)
+	}
+	return credentials.Value{
+		AccessKeyID:     resp.AccessKeyID,
+		SecretAccessKey: resp.SecretAccessKey,
+	}, nil
+}
+
+// Write sends credentials to the helper to store
+func (e *ExecStore) Write(profile string, creds credentials.Value) error {
+	logger.InfoMsgf("writing %s in exec store", profile)
+	_, err := e.run(execActionStore, execRequest{
+		Profile:         profile,
+		AccessKeyID:     creds.AccessKeyID,
+		SecretAccessKey: creds.SecretAccessKey,
+	})
+	return err
+}
+
+// Check asks the helper if it has credentials for the profile
+func (e *ExecStore) Check(profile string) bool {
+	logger.InfoMsgf("checking for %s in exec store", profile)
+	resp, err := e.run(execActionCheck, execRequest{Profile: profile})
+	if err != nil {
+		logger.DebugMsgf("exec store check failed: %s", err)
+		return false
+	}
+	return resp.Found
+}
+
+// Delete asks the helper to erase the profile
+func (e *ExecStore) Delete(profile string) error {
+	logger.InfoMsgf("deleting %s from exec store", profile)
+	_, err := e.run(execActionErase, execRequest{Profile: profile})
+	return err
+}
+
+// List asks the helper for the profiles it has stored
+func (e *ExecStore) List() ([]string, error) {
+	logger.InfoMsg("listing profiles in exec store")
+	resp, err := e.run(execActionList, execRequest{})
+	if err != nil {
+		return []string{}, err
+	}
+	return resp.Profiles, nil
+}
+
+func (e *ExecStore) run(action string, req execRequest) (execResponse, error) {
+	if len(e.Command) == 0 {
+		return execResponse{}, fmt.Errorf("exec store command must be provided")
+	}
+	input, err := json.Marshal(req)
+	if err != nil {
+		return execResponse{}, err
+	}
+
+	args := append(append([]string{}, e.Command[1:]...), action)
+	cmd := exec.Command(e.Command[0], args...)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stderr = os.Stderr
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+
+	runErr := cmd.Run()
+	resp := execResponse{}
+	if output := strings.TrimSpace(stdout.String()); output != "" {
+		if err := json.Unmarshal([]byte(output), &resp); err != nil && runErr == nil {
+			return execResponse{}, fmt.Errorf("exec store %s returned invalid json: %s", action, err)
+		}
+	}
+	if resp.Error != "" {
+		return execResponse{}, fmt.Errorf("exec store %s failed: %s", action, resp.Error)
+	}
+	if runErr != nil {
+		return execResponse{}, fmt.Errorf("exec store %s failed: %s", action, runErr)
+	}
+	return resp, nil
+}
@@ -0,0 +1,79 @@
+package profiles
+
+import (
+	"os/exec"
+	"slices"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws/credentials"
+)
+
+func testExecStore(t *testing.T) *ExecStore {
+	t.Helper()
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh is required for the exec store helper")
+	}
+	t.Setenv("EXEC_STORE_DIR", t.TempDir())
+	return &ExecStore{Command: []string{"sh", "testdata/exec_helper.sh"}}
+}
+
+func TestExecStoreRoundTrip(t *testing.T) {
+	e := testExecStore(t)
+	value := credentials.Value{AccessKeyID: "AKIAEXAMPLE", SecretAccessKey: "secret"}
+
+	if e.Check("auth") {
+		t.Error("check found a profile before it was written")
+	}
+	if err := e.Write("auth", value); err != nil {
+		t.Fatal(err)
+	}
+	if !e.Check("auth") {
+		t.Error("check did not find a written profile")
+	}
+	result, err := e.Lookup("auth")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.AccessKeyID != value.AccessKeyID || result.SecretAccessKey != value.SecretAccessKey {
+		t.Errorf("unexpected lookup result: %+v", result)
+	}
+
+	if err := e.Write("other", value); err != nil {
+		t.Fatal(err)
+	}
+	names, err := e.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	slices.Sort(names)
+	if !slices.Equal(names, []string{"auth", "other"}) {
+		t.Errorf("unexpected profile list: %v", names)
+	}
+
+	if err := e.Delete("auth"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.Lookup("auth"); err == nil {
+		t.Error("lookup succeeded after delete")
+	}
+}
+
+func TestExecStoreErrors(t *testing.T) {
+	e := testExecStore(t)
+
+	if _, err := e.Lookup("missing"); err == nil {
+		t.Error("expected error field to fail lookup")
+	}
+	if _, err := e.Lookup("garbage"); err == nil {
+		t.Error("expected invalid json to fail lookup")
+	}
+	if err := e.Write("readonly", credentials.Value{AccessKeyID: "a", SecretAccessKey: "b"}); err == nil {
+		t.Error("expected non-zero exit to fail write")
+	}
+	if _, err := e.run("bogus", execRequest{}); err == nil {
+		t.Error("expected unknown action to fail")
+	}
+	if _, err := (&ExecStore{}).Lookup("auth"); err == nil {
+		t.Error("expected missing command to fail")
+	}
+}
@@ -3,14 +3,26 @@ package profiles
 import (
 	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/99designs/keyring"
 	"github.com/aws/aws-sdk-go/aws/credentials"
 )
 
+// DefaultKeyringName is the keyring used when no name is set
+const DefaultKeyringName = "default"
+
 // KeyringStore fetches credentials from the system keyring
+// When only the file backend is available, items are encrypted with a passphrase read from
+// the Askpass command or the terminal, and the unlock is cached for UnlockLifetime
+// NonInteractive disables the terminal prompt, for processes which have no terminal
 type KeyringStore struct {
-	Name string
+	Name           string
+	Askpass        []string
+	UnlockLifetime time.Duration
+	NonInteractive bool
+
+	passphrase string
 }
 
 // Lookup checks the keyring for credentials
@@ -83,7 +95,7 @@ func (k *KeyringStore) config() keyring.Config {
 		},
 		KeychainName:            "login",
 		LibSecretCollectionName: "voyager:" + k.getName(),
-		FilePasswordFunc:        filePasswordShim,
+		FilePasswordFunc:        k.filePassword,
 		FileDir:                 "~/.voyager/" + k.getName(),
 		ServiceName:             "voyager:" + k.getName(),
 	}
@@ -92,7 +104,7 @@ func (k *KeyringStore) config() keyring.Config {
 func (k *KeyringStore) getName() string {
 	if k.Name == "" {
 		logger.InfoMsgf("set keyring store to default")
-		k.Name = "default"
+		k.Name = DefaultKeyringName
 	}
 	return k.Name
 }
@@ -120,7 +132,3 @@ func (k *KeyringStore) itemName(profile string) string {
 func (k *KeyringStore) keyring() (keyring.Keyring, error) {
 	return keyring.Open(k.config())
 }
-
-func filePasswordShim(_ string) (string, error) {
-	return "", nil
-}
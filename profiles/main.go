@@ -14,12 +14,27 @@ type Store interface {
 	Delete(string) error
 }
 
+// ListableStore defines a backend which can list the profiles it holds
+type ListableStore interface {
+	List() ([]string, error)
+}
+
 // NewDefaultStore returns the default backend set
 func NewDefaultStore() Store {
-	logger.InfoMsg("initializing the default profiles store")
+	return NewNamedStore("")
+}
+
+// NewNamedStore returns the default backend set, using the named keyring
+func NewNamedStore(name string) Store {
+	return NewStoreWithKeyring(&KeyringStore{Name: name})
+}
+
+// NewStoreWithKeyring returns the default backend set, using the provided keyring
+func NewStoreWithKeyring(k *KeyringStore) Store {
+	logger.InfoMsgf("initializing the default profiles store with keyring %s", k.Name)
 	return &MultiStore{
 		Backends: []Store{
-			&KeyringStore{},
+			k,
 			&PromptStore{},
 		},
 	}
@@ -7,8 +7,11 @@ import (
 )
 
 // MultiStore is a storage backend which tries a series of backends
+// Credentials found in a later backend are written to the first writable backend before it,
+// unless NoWriteForward is set
 type MultiStore struct {
-	Backends []Store
+	Backends       []Store
+	NoWriteForward bool
 }
 
 // Lookup looks up creds from the list of backends
@@ -32,7 +35,7 @@ func (m *MultiStore) Lookup(profile string) (credentials.Value, error) {
 	}
 
 	writeIndex, writer := m.getWriter()
-	if writer != nil && writeIndex < readIndex {
+	if !m.NoWriteForward && writer != nil && writeIndex < readIndex {
 		logger.InfoMsg("writing forward to earlier backend")
 		err := writer.Write(profile, creds)
 		if err != nil {
@@ -74,9 +77,16 @@ func (m *MultiStore) Write(s string, c credentials.Value) error {
 	return writer.Write(s, c)
 }
 
+// getWriter returns the first backend which can save credentials
+// Nested MultiStores only count if they contain a writer themselves
 func (m *MultiStore) getWriter() (int, WritableStore) {
 	logger.InfoMsgf("looking up writer in backends")
 	for index, item := range m.Backends {
+		if nested, ok := item.(*MultiStore); ok {
+			if _, writer := nested.getWriter(); writer == nil {
+				continue
+			}
+		}
 		writer, ok := item.(WritableStore)
 		if ok {
 			logger.InfoMsgf("found writer in %d backend", index)
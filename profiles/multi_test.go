@@ -0,0 +1,75 @@
+package profiles
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws/credentials"
+)
+
+// memoryStore is a writable store backed by a map
+type memoryStore struct {
+	values map[string]credentials.Value
+}
+
+func (s *memoryStore) Lookup(profile string) (credentials.Value, error) {
+	value, ok := s.values[profile]
+	if !ok {
+		return credentials.Value{}, credentials.ErrNoValidProvidersFoundInChain
+	}
+	return value, nil
+}
+
+func (s *memoryStore) Check(profile string) bool {
+	_, ok := s.values[profile]
+	return ok
+}
+
+func (s *memoryStore) Delete(profile string) error {
+	delete(s.values, profile)
+	return nil
+}
+
+func (s *memoryStore) Write(profile string, value credentials.Value) error {
+	if s.values == nil {
+		s.values = map[string]credentials.Value{}
+	}
+	s.values[profile] = value
+	return nil
+}
+
+func TestMultiStoreSkipsNestedStoresWithoutWriters(t *testing.T) {
+	t.Setenv("VOYAGER_PROFILE_AUTH_ACCESS_KEY_ID", "AKIAENV")
+	t.Setenv("VOYAGER_PROFILE_AUTH_SECRET_ACCESS_KEY", "secret")
+
+	store := &MultiStore{Backends: []Store{
+		&MultiStore{Backends: []Store{&PromptStore{}}},
+		&EnvStore{},
+	}}
+	value, err := store.Lookup("auth")
+	if err != nil {
+		t.Fatalf("lookup failed: %s", err)
+	}
+	if value.AccessKeyID != "AKIAENV" {
+		t.Errorf("unexpected credentials: %+v", value)
+	}
+	if err := store.Write("auth", value); err == nil {
+		t.Error("expected write to fail without a writer")
+	}
+}
+
+func TestMultiStoreWritesForwardIntoNestedStore(t *testing.T) {
+	t.Setenv("VOYAGER_PROFILE_AUTH_ACCESS_KEY_ID", "AKIAENV")
+	t.Setenv("VOYAGER_PROFILE_AUTH_SECRET_ACCESS_KEY", "secret")
+
+	memory := &memoryStore{}
+	store := &MultiStore{Backends: []Store{
+		&MultiStore{Backends: []Store{memory}},
+		&EnvStore{},
+	}}
+	if _, err := store.Lookup("auth"); err != nil {
+		t.Fatal(err)
+	}
+	if memory.values["auth"].AccessKeyID != "AKIAENV" {
+		t.Error("credentials were not written forward into the nested store")
+	}
+}
@@ -0,0 +1,51 @@
+//go:build linux
+
+package profiles
+
+import (
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// keyPerm grants the possessor and the owning user view, read, write, search, link, and setattr
+const keyPerm = 0x3f3f0000
+
+// cachePassphrase stores the passphrase in the user's kernel keyring, expiring after lifetime
+func cachePassphrase(name, passphrase string, lifetime time.Duration) error {
+	id, err := unix.AddKey("user", name, []byte(passphrase), unix.KEY_SPEC_USER_KEYRING)
+	if err != nil {
+		return err
+	}
+	if err := unix.KeyctlSetperm(id, keyPerm); err != nil {
+		return err
+	}
+	_, err = unix.KeyctlInt(unix.KEYCTL_SET_TIMEOUT, id, int(lifetime.Seconds()), 0, 0)
+	return err
+}
+
+func loadCachedPassphrase(name string) (string, bool) {
+	id, err := unix.KeyctlSearch(unix.KEY_SPEC_USER_KEYRING, "user", name, 0)
+	if err != nil {
+		return "", false
+	}
+	size, err := unix.KeyctlBuffer(unix.KEYCTL_READ, id, nil, 0)
+	if err != nil {
+		return "", false
+	}
+	buf := make([]byte, size)
+	size, err = unix.KeyctlBuffer(unix.KEYCTL_READ, id, buf, 0)
+	if err != nil || size == 0 {
+		return "", false
+	}
+	return string(buf[:size]), true
+}
+
+func clearCachedPassphrase(name string) error {
+	id, err := unix.KeyctlSearch(unix.KEY_SPEC_USER_KEYRING, "user", name, 0)
+	if err != nil {
+		return nil
+	}
+	_, err = unix.KeyctlInt(unix.KEYCTL_UNLINK, id, unix.KEY_SPEC_USER_KEYRING, 0, 0)
+	return err
+}
@@ -0,0 +1,21 @@
+//go:build !linux
+
+package profiles
+
+import (
+	"time"
+)
+
+// cachePassphrase is a no-op where no kernel keyring is available, so the
+// passphrase is only kept for the life of the process
+func cachePassphrase(_, _ string, _ time.Duration) error {
+	return nil
+}
+
+func loadCachedPassphrase(_ string) (string, bool) {
+	return "", false
+}
+
+func clearCachedPassphrase(_ string) error {
+	return nil
+}
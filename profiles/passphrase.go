@@ -0,0 +1,221 @@
+package profiles
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/akerl/voyager/v3/tty"
+
+	"github.com/99designs/keyring"
+	"github.com/akerl/speculate/v2/creds"
+)
+
+const (
+	// AskpassEnvVar names a command which prints the file keyring passphrase
+	AskpassEnvVar = "VOYAGER_ASKPASS"
+
+	// DefaultUnlockLifetime is how long an unlocked file keyring stays unlocked
+	DefaultUnlockLifetime = time.Hour
+)
+
+// filePassword returns the passphrase for the file backend
+// It uses the cached unlock if present, and otherwise reads the passphrase, checks it
+// against existing items, and re-encrypts any items stored with an empty passphrase
+func (k *KeyringStore) filePassword(_ string) (string, error) {
+	if k.passphrase != "" {
+		return k.passphrase, nil
+	}
+	if cached, ok := loadCachedPassphrase(k.cacheKey()); ok {
+		logger.InfoMsg("using cached file keyring passphrase")
+		k.passphrase = cached
+		return cached, nil
+	}
+
+	protected, legacy, err := k.scanFileItems()
+	if err != nil {
+		return "", err
+	}
+
+	passphrase, err := k.readPassphrase(len(protected) == 0)
+	if err != nil {
+		return "", err
+	}
+	if len(protected) != 0 {
+		ring, err := k.fileRing(passphrase)
+		if err != nil {
+			return "", err
+		}
+		if _, err := ring.Get(protected[0]); err != nil {
+			return "", fmt.Errorf("incorrect passphrase for keyring %s", k.getName())
+		}
+	}
+	if err := k.migrateFileItems(legacy, passphrase); err != nil {
+		return "", err
+	}
+
+	k.passphrase = passphrase
+	if lifetime := k.getUnlockLifetime(); lifetime > 0 {
+		if err := cachePassphrase(k.cacheKey(), passphrase, lifetime); err != nil {
+			logger.InfoMsgf("failed to cache file keyring passphrase: %s", err)
+		}
+	}
+	return passphrase, nil
+}
+
+// Lock forgets the cached file keyring passphrase
+func (k *KeyringStore) Lock() error {
+	k.passphrase = ""
+	return clearCachedPassphrase(k.cacheKey())
+}
+
+// LockKeyrings locks every KeyringStore in a store, including those in nested MultiStores
+func LockKeyrings(s Store) error {
+	switch store := s.(type) {
+	case *KeyringStore:
+		logger.InfoMsgf("locking keyring %s", store.getName())
+		return store.Lock()
+	case *MultiStore:
+		for _, item := range store.Backends {
+			if err := LockKeyrings(item); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (k *KeyringStore) readPassphrase(isNew bool) (string, error) {
+	askpass := k.getAskpass()
+	if len(askpass) != 0 {
+		return runAskpass(askpass, k.getName())
+	}
+	if k.NonInteractive {
+		return "", fmt.Errorf(
+			"keyring %s is locked and cannot prompt without a terminal; set keyring_askpass or unlock it first",
+			k.getName(),
+		)
+	}
+
+	if !isNew {
+		return tty.ReadPassword(fmt.Sprintf("Passphrase for keyring %s: ", k.getName()))
+	}
+	passphrase, err := tty.ReadPassword(fmt.Sprintf("New passphrase for keyring %s: ", k.getName()))
+	if err != nil {
+		return "", err
+	}
+	if passphrase == "" {
+		return "", fmt.Errorf("passphrase must not be empty")
+	}
+	confirm, err := tty.ReadPassword("Confirm passphrase: ")
+	if err != nil {
+		return "", err
+	}
+	if confirm != passphrase {
+		return "", fmt.Errorf("passphrases do not match")
+	}
+	return passphrase, nil
+}
+
+func runAskpass(command []string, name string) (string, error) {
+	logger.InfoMsgf("running askpass command: %v", command)
+	cmd := exec.Command(command[0], command[1:]...)
+	cmd.Args = append(cmd.Args, fmt.Sprintf("Passphrase for voyager keyring %s: ", name))
+	cmd.Stdin = os.Stdin
+	cmd.Stderr = os.Stderr
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("askpass command failed: %s", err)
+	}
+	passphrase := strings.TrimRight(stdout.String(), "\r\n")
+	if passphrase == "" {
+		return "", fmt.Errorf("askpass command returned an empty passphrase")
+	}
+	return passphrase, nil
+}
+
+// scanFileItems sorts the file backend's items into those protected by a passphrase
+// and those written with an empty passphrase by older versions
+func (k *KeyringStore) scanFileItems() ([]string, []string, error) {
+	legacyRing, err := k.fileRing("")
+	if err != nil {
+		return nil, nil, err
+	}
+	keys, err := legacyRing.Keys()
+	if err != nil {
+		return nil, nil, err
+	}
+	protected := []string{}
+	legacy := []string{}
+	for _, key := range keys {
+		if _, err := legacyRing.Get(key); err == nil {
+			legacy = append(legacy, key)
+		} else {
+			protected = append(protected, key)
+		}
+	}
+	return protected, legacy, nil
+}
+
+func (k *KeyringStore) migrateFileItems(keys []string, passphrase string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	tty.Println(fmt.Sprintf("Encrypting %d existing items in keyring %s with the new passphrase", len(keys), k.getName()))
+	legacyRing, err := k.fileRing("")
+	if err != nil {
+		return err
+	}
+	newRing, err := k.fileRing(passphrase)
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		logger.InfoMsgf("migrating keyring item: %s", key)
+		item, err := legacyRing.Get(key)
+		if err != nil {
+			return err
+		}
+		if err := newRing.Set(item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fileRing opens the file backend directly with a fixed passphrase
+func (k *KeyringStore) fileRing(passphrase string) (keyring.Keyring, error) {
+	config := k.config()
+	config.AllowedBackends = []keyring.BackendType{keyring.FileBackend}
+	config.FilePasswordFunc = keyring.FixedStringPrompt(passphrase)
+	return keyring.Open(config)
+}
+
+func (k *KeyringStore) cacheKey() string {
+	return "voyager:" + k.getName() + ":passphrase"
+}
+
+func (k *KeyringStore) getAskpass() []string {
+	if len(k.Askpass) == 0 {
+		if command := os.Getenv(AskpassEnvVar); command != "" {
+			parsed, err := creds.StringToCommand(command)
+			if err != nil {
+				logger.InfoMsgf("ignoring invalid askpass command: %s", err)
+				return nil
+			}
+			k.Askpass = parsed
+		}
+	}
+	return k.Askpass
+}
+
+func (k *KeyringStore) getUnlockLifetime() time.Duration {
+	if k.UnlockLifetime == 0 {
+		return DefaultUnlockLifetime
+	}
+	return k.UnlockLifetime
+}
@@ -0,0 +1,27 @@
+package profiles
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLockKeyrings(t *testing.T) {
+	k := &KeyringStore{Name: "voyager-lock-test", passphrase: "hunter2"}
+	if err := cachePassphrase(k.cacheKey(), "hunter2", time.Minute); err != nil {
+		t.Skipf("passphrase cache unavailable: %s", err)
+	}
+	store := &MultiStore{Backends: []Store{
+		&PromptStore{},
+		&MultiStore{Backends: []Store{k}},
+	}}
+
+	if err := LockKeyrings(store); err != nil {
+		t.Fatal(err)
+	}
+	if k.passphrase != "" {
+		t.Error("in-memory passphrase was not cleared")
+	}
+	if _, ok := loadCachedPassphrase(k.cacheKey()); ok {
+		t.Error("cached passphrase was not cleared")
+	}
+}
@@ -1,26 +1,28 @@
 package profiles
 
 import (
-	"bufio"
 	"fmt"
-	"os"
 	"strings"
 
+	"github.com/akerl/voyager/v3/tty"
+
 	"github.com/aws/aws-sdk-go/aws/credentials"
 )
 
 // PromptStore is a storage backend which asks the user for input
+// Prompts are shown on the terminal so stdin and stdout stay free for other tools,
+// and the secret key is read without echo
 type PromptStore struct{}
 
 // Lookup asks the user for credentials
 func (p *PromptStore) Lookup(profile string) (credentials.Value, error) {
 	logger.InfoMsgf("looking up %s in prompt store", profile)
-	fmt.Printf("Please enter your credentials for profile: %s\n", profile)
-	accessKey, err := p.getUserInput("AWS Access Key: ")
+	tty.Println(fmt.Sprintf("Please enter your credentials for profile: %s", profile))
+	accessKey, err := p.getUserInput("AWS Access Key: ", tty.ReadLine)
 	if err != nil {
 		return credentials.Value{}, err
 	}
-	secretKey, err := p.getUserInput("AWS Secret Key: ")
+	secretKey, err := p.getUserInput("AWS Secret Key: ", tty.ReadPassword)
 	if err != nil {
 		return credentials.Value{}, err
 	}
@@ -40,10 +42,8 @@ func (p *PromptStore) Delete(_ string) error {
 	return nil
 }
 
-func (p *PromptStore) getUserInput(message string) (string, error) {
-	infoReader := bufio.NewReader(os.Stdin)
-	fmt.Fprint(os.Stderr, message)
-	info, err := infoReader.ReadString('\n')
+func (p *PromptStore) getUserInput(message string, reader func(string) (string, error)) (string, error) {
+	info, err := reader(message)
 	if err != nil {
 		return "", err
 	}
@@ -53,3 +53,34 @@ func (p *PromptStore) getUserInput(message string) (string, error) {
 	}
 	return info, nil
 }
+
+// WithoutPrompts returns the store with any PromptStore backends removed, for processes
+// which have no terminal to prompt on
+// An error is returned if no other backends remain
+func WithoutPrompts(s Store) (Store, error) {
+	result := removePrompts(s)
+	if result == nil {
+		return nil, fmt.Errorf("store only prompts for credentials, which requires a terminal")
+	}
+	return result, nil
+}
+
+func removePrompts(s Store) Store {
+	switch store := s.(type) {
+	case *PromptStore:
+		return nil
+	case *MultiStore:
+		m := &MultiStore{NoWriteForward: store.NoWriteForward}
+		for _, item := range store.Backends {
+			if backend := removePrompts(item); backend != nil {
+				m.Backends = append(m.Backends, backend)
+			}
+		}
+		if len(m.Backends) == 0 {
+			return nil
+		}
+		return m
+	default:
+		return s
+	}
+}
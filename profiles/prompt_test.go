@@ -0,0 +1,28 @@
+package profiles
+
+import (
+	"testing"
+)
+
+func TestWithoutPrompts(t *testing.T) {
+	store := &MultiStore{Backends: []Store{
+		&KeyringStore{},
+		&PromptStore{},
+		&MultiStore{Backends: []Store{&PromptStore{}}},
+	}}
+	result, err := WithoutPrompts(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, ok := result.(*MultiStore)
+	if !ok || len(m.Backends) != 1 {
+		t.Fatalf("expected only the keyring backend, got %+v", result)
+	}
+	if _, ok := m.Backends[0].(*KeyringStore); !ok {
+		t.Errorf("expected keyring backend, got %T", m.Backends[0])
+	}
+
+	if _, err := WithoutPrompts(&MultiStore{Backends: []Store{&PromptStore{}}}); err == nil {
+		t.Error("expected error for a prompt-only store")
+	}
+}
@@ -0,0 +1,56 @@
+#!/bin/sh
+# ExecStore helper for tests, keeping each profile as a file in $EXEC_STORE_DIR
+# The "readonly" profile cannot be stored, and the "garbage" profile returns invalid JSON
+set -e
+
+action="$1"
+input="$(cat)"
+
+field() {
+    printf '%s' "$input" | sed -n "s/.*\"$1\":\"\([^\"]*\)\".*/\1/p"
+}
+
+profile="$(field profile)"
+file="$EXEC_STORE_DIR/$profile"
+
+case "$action" in
+    get)
+        if [ "$profile" = "garbage" ]; then
+            echo "not json"
+        elif [ -f "$file" ]; then
+            cat "$file"
+        else
+            echo '{"error": "profile not found"}'
+        fi
+        ;;
+    store)
+        if [ "$profile" = "readonly" ]; then
+            echo "profile is read only" >&2
+            exit 3
+        fi
+        printf '{"access_key_id": "%s", "secret_access_key": "%s"}\n' \
+            "$(field access_key_id)" "$(field secret_access_key)" > "$file"
+        ;;
+    check)
+        if [ -f "$file" ]; then
+            echo '{"found": true}'
+        else
+            echo '{}'
+        fi
+        ;;
+    erase)
+        rm -f "$file"
+        ;;
+    list)
+        names=""
+        for item in "$EXEC_STORE_DIR"/*; do
+            [ -f "$item" ] || continue
+            names="$names${names:+, }\"$(basename "$item")\""
+        done
+        echo "{\"profiles\": [$names]}"
+        ;;
+    *)
+        echo "unknown action: $action" >&2
+        exit 2
+        ;;
+esac
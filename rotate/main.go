@@ -6,8 +6,11 @@ import (
 	"strings"
 	"time"
 
+	"github.com/akerl/voyager/v3/agent"
+	"github.com/akerl/voyager/v3/audit"
 	"github.com/akerl/voyager/v3/cartogram"
 	"github.com/akerl/voyager/v3/confirm"
+	"github.com/akerl/voyager/v3/endpoint"
 	"github.com/akerl/voyager/v3/pkgver"
 	"github.com/akerl/voyager/v3/profiles"
 	"github.com/akerl/voyager/v3/travel"
@@ -160,9 +163,14 @@ func (r *Rotator) getAwsSession() (*session.Session, error) {
 	if r.validCreds.AccessKeyID == "" {
 		return nil, fmt.Errorf("no valid credentials set")
 	}
+	endpoints, err := endpoint.Default()
+	if err != nil {
+		return nil, err
+	}
 	awsConfig := aws.NewConfig().WithRegion(region).WithCredentials(
 		credentials.NewStaticCredentialsFromCreds(r.validCreds),
 	)
+	endpoints.Lookup("", region).Apply(awsConfig)
 	return session.NewSession(awsConfig)
 }
 
@@ -504,8 +512,17 @@ func (r *Rotator) testAuth() error {
 		Extra:   []string{"rotator"},
 	}}
 	opts.MfaPrompt = mfaPrompt
-	opts.Store = r.Store
+	opts.Store = r.getStore()
 	opts.SessionName = username
+	opts.Command = "rotate"
+	opts.Audit, err = audit.Default()
+	if err != nil {
+		return err
+	}
+	opts.Hooks, err = travel.LoadHooks("")
+	if err != nil {
+		return err
+	}
 
 	logger.InfoMsg("testing auth using new creds")
 	c, err := path.TraverseWithOptions(opts)
@@ -513,6 +530,13 @@ func (r *Rotator) testAuth() error {
 		return err
 	}
 
+	if client, ok := agent.FromEnv(); ok {
+		logger.InfoMsgf("forgetting agent cache entries for %s", profile)
+		if err := client.Forget(profile); err != nil {
+			return err
+		}
+	}
+
 	openURL, err := c.ToCustomConsoleURL("")
 	if err != nil {
 		return err
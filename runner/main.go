@@ -0,0 +1,93 @@
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+
+	"github.com/akerl/timber/v2/log"
+)
+
+const (
+	signalExitBase = 128
+)
+
+var logger = log.NewLogger("voyager")
+
+// forwardedSignals are passed on to the child
+var forwardedSignals = []os.Signal{
+	syscall.SIGTERM,
+	syscall.SIGHUP,
+}
+
+// terminalSignals already reach the child from the terminal, which shares its process group,
+// so voyager only stops them from killing itself while the child runs
+var terminalSignals = []os.Signal{
+	os.Interrupt,
+	syscall.SIGQUIT,
+}
+
+// Run executes a command with the provided environment
+// Stdin, stdout, and stderr are passed through, SIGTERM and SIGHUP received by voyager are
+// forwarded to the child, and the child's exit code is returned
+func Run(command []string, env []string) (int, error) {
+	if len(command) == 0 {
+		return 1, fmt.Errorf("no command provided")
+	}
+	logger.InfoMsgf("running command: %v", command)
+
+	cmd := exec.Command(command[0], command[1:]...)
+	cmd.Env = env
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, forwardedSignals...)
+	termCh := make(chan os.Signal, 1)
+	signal.Notify(termCh, terminalSignals...)
+	defer func() {
+		signal.Stop(sigCh)
+		signal.Stop(termCh)
+		close(sigCh)
+		close(termCh)
+	}()
+
+	if err := cmd.Start(); err != nil {
+		return 1, err
+	}
+
+	go func() {
+		for sig := range sigCh {
+			logger.InfoMsgf("forwarding signal: %s", sig)
+			if err := cmd.Process.Signal(sig); err != nil {
+				logger.InfoMsgf("failed to forward signal: %s", err)
+			}
+		}
+	}()
+	go func() {
+		for sig := range termCh {
+			logger.InfoMsgf("leaving terminal signal to the child: %s", sig)
+		}
+	}()
+
+	err := cmd.Wait()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitCode(exitErr.ProcessState), nil
+	}
+	if err != nil {
+		return 1, err
+	}
+	return 0, nil
+}
+
+func exitCode(state *os.ProcessState) int {
+	if status, ok := state.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+		return signalExitBase + int(status.Signal())
+	}
+	return state.ExitCode()
+}
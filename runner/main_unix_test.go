@@ -0,0 +1,31 @@
+//go:build !windows
+
+package runner
+
+import (
+	"os"
+	"testing"
+)
+
+func TestRunLeavesInterruptToTerminal(t *testing.T) {
+	// The child interrupts voyager, then exits with the number of interrupts it received
+	script := `count=0; trap 'count=$((count+1))' INT; kill -INT $PPID; sleep 1; exit $count`
+	code, err := Run([]string{"sh", "-c", script}, os.Environ())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code != 0 {
+		t.Errorf("interrupt was forwarded to the child %d times", code)
+	}
+}
+
+func TestRunForwardsTerminate(t *testing.T) {
+	script := `trap 'exit 7' TERM; kill -TERM $PPID; sleep 5 & wait`
+	code, err := Run([]string{"sh", "-c", script}, os.Environ())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code != 7 {
+		t.Errorf("child exited with %d instead of handling the forwarded SIGTERM", code)
+	}
+}
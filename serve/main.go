@@ -0,0 +1,210 @@
+package serve
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/akerl/voyager/v3/travel"
+
+	"github.com/akerl/timber/v2/log"
+)
+
+const (
+	defaultAddress = "127.0.0.1:0"
+	credsPath      = "/creds"
+	tokenBytes     = 32
+	retryInterval  = 30 * time.Second
+)
+
+var logger = log.NewLogger("voyager")
+
+// Server provides credentials for a path over the AWS container credentials protocol
+// Credentials are re-traversed before they expire, reusing any hops which are still
+// valid in the cache so that MFA is only requested once its cached hop has lapsed
+type Server struct {
+	Path     travel.Path
+	Options  travel.TraverseOptions
+	Address  string
+	Token    string
+	listener net.Listener
+	creds    travel.Creds
+	refresh  *refreshCall
+	lock     sync.Mutex
+}
+
+// refreshCall tracks an in-flight traversal, so concurrent requests share its result
+type refreshCall struct {
+	done  chan struct{}
+	creds travel.Creds
+	err   error
+}
+
+type containerCreds struct {
+	AccessKeyID     string `json:"AccessKeyId"`
+	SecretAccessKey string `json:"SecretAccessKey"`
+	Token           string `json:"Token"`
+	Expiration      string `json:"Expiration,omitempty"`
+}
+
+// Start opens the listener and fetches the initial credentials
+func (s *Server) Start() error {
+	if s.Token == "" {
+		buffer := make([]byte, tokenBytes)
+		if _, err := rand.Read(buffer); err != nil {
+			return err
+		}
+		s.Token = hex.EncodeToString(buffer)
+	}
+	if s.Address == "" {
+		s.Address = defaultAddress
+	}
+
+	if _, err := s.current(); err != nil {
+		return err
+	}
+
+	logger.InfoMsgf("starting listener on %s", s.Address)
+	listener, err := net.Listen("tcp", s.Address)
+	if err != nil {
+		return err
+	}
+	s.listener = listener
+	return nil
+}
+
+// URL returns the full URI for the credentials endpoint
+func (s *Server) URL() string {
+	return fmt.Sprintf("http://%s%s", s.listener.Addr(), credsPath)
+}
+
+// EnvVars returns the environment variables which point AWS SDKs at the server
+func (s *Server) EnvVars() map[string]string {
+	return map[string]string{
+		"AWS_CONTAINER_CREDENTIALS_FULL_URI": s.URL(),
+		"AWS_CONTAINER_AUTHORIZATION_TOKEN":  s.Token,
+	}
+}
+
+// Serve handles requests until the listener is closed
+func (s *Server) Serve() error {
+	go s.refreshLoop()
+	mux := http.NewServeMux()
+	mux.HandleFunc(credsPath, s.handle)
+	return http.Serve(s.listener, mux)
+}
+
+// Close stops the listener
+func (s *Server) Close() error {
+	return s.listener.Close()
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	logger.InfoMsgf("received %s request from %s", r.Method, r.RemoteAddr)
+	auth := r.Header.Get("Authorization")
+	if subtle.ConstantTimeCompare([]byte(auth), []byte(s.Token)) != 1 {
+		http.Error(w, "invalid authorization token", http.StatusUnauthorized)
+		return
+	}
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	c, err := s.current()
+	if err != nil {
+		logger.InfoMsgf("failed to load credentials: %s", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	cc := containerCreds{
+		AccessKeyID:     c.AccessKey,
+		SecretAccessKey: c.SecretKey,
+		Token:           c.SessionToken,
+	}
+	if !c.Expiration.IsZero() {
+		cc.Expiration = c.Expiration.UTC().Format(time.RFC3339)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(cc); err != nil {
+		logger.InfoMsgf("failed to write response: %s", err)
+	}
+}
+
+// current returns the server's credentials, refreshing them if they are due
+// The lock is not held during traversal, so requests made while a refresh is
+// waiting on MFA are served the existing credentials until they expire
+func (s *Server) current() (travel.Creds, error) {
+	s.lock.Lock()
+	if s.creds.AccessKey != "" && !s.creds.ExpiresWithin(travel.RefreshWindow) {
+		c := s.creds
+		s.lock.Unlock()
+		return c, nil
+	}
+	call := s.startRefresh()
+	if s.creds.AccessKey != "" && !s.creds.Expired() {
+		c := s.creds
+		s.lock.Unlock()
+		return c, nil
+	}
+	s.lock.Unlock()
+
+	<-call.done
+	return call.creds, call.err
+}
+
+// startRefresh returns the in-flight refresh, starting one if needed
+// The caller must hold the lock
+func (s *Server) startRefresh() *refreshCall {
+	if s.refresh == nil {
+		s.refresh = &refreshCall{done: make(chan struct{})}
+		go s.runRefresh(s.refresh)
+	}
+	return s.refresh
+}
+
+func (s *Server) runRefresh(call *refreshCall) {
+	logger.InfoMsg("traversing path for fresh credentials")
+	call.creds, call.err = s.Path.TraverseWithContext(context.Background(), s.Options)
+
+	s.lock.Lock()
+	if call.err == nil {
+		s.creds = call.creds
+	}
+	s.refresh = nil
+	s.lock.Unlock()
+	close(call.done)
+}
+
+func (s *Server) refreshLoop() {
+	for {
+		s.lock.Lock()
+		expiration := s.creds.Expiration
+		s.lock.Unlock()
+		if expiration.IsZero() {
+			logger.InfoMsg("credentials do not expire; stopping refresh loop")
+			return
+		}
+
+		wait := time.Until(expiration.Add(-travel.RefreshWindow))
+		logger.InfoMsgf("next refresh in %s", wait)
+		time.Sleep(wait)
+
+		s.lock.Lock()
+		call := s.startRefresh()
+		s.lock.Unlock()
+		<-call.done
+		if err := call.err; err != nil {
+			logger.InfoMsgf("background refresh failed: %s", err)
+			time.Sleep(retryInterval)
+		}
+	}
+}
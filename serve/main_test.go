@@ -0,0 +1,86 @@
+package serve
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/akerl/voyager/v3/cartogram"
+	"github.com/akerl/voyager/v3/travel"
+
+	"github.com/akerl/speculate/v2/creds"
+)
+
+type blockingTraverser struct {
+	calls   int32
+	release chan struct{}
+}
+
+func (b *blockingTraverser) TraverseWithContext(_ context.Context, _ travel.Path, _ travel.TraverseOptions) (travel.Creds, error) {
+	atomic.AddInt32(&b.calls, 1)
+	<-b.release
+	return travel.Creds{
+		Creds:      creds.Creds{AccessKey: "fresh"},
+		Expiration: time.Now().Add(time.Hour),
+	}, nil
+}
+
+func testServer(b *blockingTraverser) *Server {
+	return &Server{
+		Path:    travel.Path{{Profile: "test"}, {Account: cartogram.Account{Account: "123456789012"}, Role: "admin"}},
+		Options: travel.TraverseOptions{Delegate: b},
+	}
+}
+
+func TestCurrentSharesRefresh(t *testing.T) {
+	b := &blockingTraverser{release: make(chan struct{})}
+	s := testServer(b)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c, err := s.current()
+			if err != nil || c.AccessKey != "fresh" {
+				t.Errorf("unexpected result: %+v, %s", c, err)
+			}
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(b.release)
+	wg.Wait()
+
+	if calls := atomic.LoadInt32(&b.calls); calls != 1 {
+		t.Errorf("expected 1 traversal, got %d", calls)
+	}
+}
+
+func TestCurrentServesValidCredsDuringRefresh(t *testing.T) {
+	b := &blockingTraverser{release: make(chan struct{})}
+	s := testServer(b)
+	s.creds = travel.Creds{
+		Creds:      creds.Creds{AccessKey: "stale"},
+		Expiration: time.Now().Add(time.Minute),
+	}
+
+	c, err := s.current()
+	if err != nil || c.AccessKey != "stale" {
+		t.Fatalf("expected existing credentials while refreshing, got %+v, %s", c, err)
+	}
+
+	close(b.release)
+	s.lock.Lock()
+	call := s.refresh
+	s.lock.Unlock()
+	if call != nil {
+		<-call.done
+	}
+
+	c, err = s.current()
+	if err != nil || c.AccessKey != "fresh" {
+		t.Errorf("expected refreshed credentials, got %+v, %s", c, err)
+	}
+}
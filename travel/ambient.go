@@ -0,0 +1,146 @@
+package travel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/defaults"
+	"github.com/aws/aws-sdk-go/aws/session"
+)
+
+// AmbientOrigin is the origin name for paths starting from the machine's own credentials
+const AmbientOrigin = "ambient"
+
+// DefaultAmbientProvider returns the chain used for ambient origins: environment variables,
+// then container credentials, then the EC2 instance metadata service
+// Credential environment variables are read when this is called, so callers that clear
+// them should capture the provider first. The SDK session for the container and metadata
+// endpoints is only created if the environment has no credentials and the chain is used
+func DefaultAmbientProvider() credentials.Provider {
+	providers := []credentials.Provider{}
+	envProvider := &credentials.EnvProvider{}
+	if value, err := envProvider.Retrieve(); err == nil {
+		providers = append(providers, &credentials.StaticProvider{Value: value})
+	}
+	providers = append(providers, &remoteProvider{})
+	return &credentials.ChainProvider{
+		VerboseErrors: true,
+		Providers:     providers,
+	}
+}
+
+// remoteProvider wraps the SDK's container and instance metadata provider, creating it on first use
+type remoteProvider struct {
+	provider credentials.Provider
+	err      error
+	once     sync.Once
+}
+
+func (r *remoteProvider) init() error {
+	r.once.Do(func() {
+		logger.InfoMsg("creating session for remote ambient credentials")
+		sess, err := session.NewSession()
+		if err != nil {
+			r.err = err
+			return
+		}
+		r.provider = defaults.RemoteCredProvider(*sess.Config, sess.Handlers)
+	})
+	return r.err
+}
+
+// Retrieve loads credentials from the container or instance metadata endpoint
+func (r *remoteProvider) Retrieve() (credentials.Value, error) {
+	if err := r.init(); err != nil {
+		return credentials.Value{}, err
+	}
+	return r.provider.Retrieve()
+}
+
+// IsExpired returns true if the credentials need to be retrieved again
+func (r *remoteProvider) IsExpired() bool {
+	if err := r.init(); err != nil {
+		return true
+	}
+	return r.provider.IsExpired()
+}
+
+// ExpiresAt returns the expiration of the last retrieved credentials
+func (r *remoteProvider) ExpiresAt() time.Time {
+	if err := r.init(); err != nil {
+		return time.Time{}
+	}
+	if expirer, ok := r.provider.(credentials.Expirer); ok {
+		return expirer.ExpiresAt()
+	}
+	return time.Time{}
+}
+
+// ambientCreds loads credentials from the ambient provider
+func ambientCreds(opts TraverseOptions) (Creds, error) {
+	provider := opts.Ambient
+	if provider == nil {
+		provider = DefaultAmbientProvider()
+	}
+	logger.InfoMsg("loading ambient credentials")
+
+	// Chains are walked here, since ChainProvider does not report expiration
+	providers := []credentials.Provider{provider}
+	if chain, ok := provider.(*credentials.ChainProvider); ok {
+		providers = chain.Providers
+	}
+	errs := []error{}
+	for _, item := range providers {
+		value, err := item.Retrieve()
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		c := Creds{}
+		c.AccessKey = value.AccessKeyID
+		c.SecretKey = value.SecretAccessKey
+		c.SessionToken = value.SessionToken
+		if expirer, ok := item.(credentials.Expirer); ok {
+			c.Expiration = expirer.ExpiresAt()
+		}
+		logger.InfoMsgf("loaded ambient credentials from %s", value.ProviderName)
+		return c, nil
+	}
+	return Creds{}, fmt.Errorf("no ambient credentials found: %w", errors.Join(errs...))
+}
+
+// isRoleSession checks if ambient credentials are a role session, in which case
+// the next hop is a chained role assumption
+// Credentials without a session token are long-lived keys, and otherwise the
+// caller identity is checked, assuming a role session if the lookup fails
+func isRoleSession(ctx context.Context, c Creds, next Hop, opts TraverseOptions) bool {
+	if c.SessionToken == "" {
+		return false
+	}
+	c.Region = next.Account.Region
+	if c.Region == "" {
+		c.Region = "us-east-1"
+	}
+	settings, err := opts.endpointSettings(next.Account.Account, c.Region)
+	if err != nil {
+		logger.InfoMsgf("failed to load endpoint settings, assuming role session: %s", err)
+		return true
+	}
+	stsClient, err := settings.STSClient(c.Creds)
+	if err != nil {
+		logger.InfoMsgf("failed to create sts client, assuming role session: %s", err)
+		return true
+	}
+	arn, err := (&identity{ctx: ctx, client: stsClient}).lookup()
+	if err != nil {
+		logger.InfoMsgf("failed to look up ambient identity, assuming role session: %s", err)
+		return true
+	}
+	logger.InfoMsgf("ambient identity is %s", arn)
+	return strings.Contains(arn, ":assumed-role/")
+}
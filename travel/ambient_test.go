@@ -0,0 +1,120 @@
+package travel
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/akerl/voyager/v3/cartogram"
+
+	"github.com/akerl/speculate/v2/creds"
+)
+
+func TestIsRoleSession(t *testing.T) {
+	fake := newFakeSTS(t)
+	opts := TraverseOptions{Endpoints: fake.endpoints()}
+	next := Hop{Account: cartogram.Account{Account: "123456789012"}, Role: "admin"}
+
+	static := Creds{Creds: creds.Creds{AccessKey: "AKIA", SecretKey: "secret"}}
+	if isRoleSession(context.Background(), static, next, opts) {
+		t.Error("keys without a session token were treated as a role session")
+	}
+	if calls := fake.calls("GetCallerIdentity"); calls != 0 {
+		t.Errorf("expected no identity lookup for static keys, got %d", calls)
+	}
+
+	session := Creds{Creds: creds.Creds{AccessKey: "ASIA", SecretKey: "secret", SessionToken: "token"}}
+	if isRoleSession(context.Background(), session, next, opts) {
+		t.Error("a user session token was treated as a role session")
+	}
+
+	fake.CallerArn = "arn:aws:sts::123456789012:assumed-role/instance/i-0123456789"
+	if !isRoleSession(context.Background(), session, next, opts) {
+		t.Error("an assumed role session was not treated as a role session")
+	}
+}
+
+// fakeMetadata serves instance role credentials like the EC2 instance metadata service
+func fakeMetadata(t *testing.T, requests *int32) {
+	t.Helper()
+	expiration := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(requests, 1)
+		switch r.URL.Path {
+		case "/latest/api/token":
+			w.Header().Set("x-aws-ec2-metadata-token-ttl-seconds", "21600")
+			fmt.Fprint(w, "imds-token")
+		case "/latest/meta-data/iam/security-credentials/":
+			fmt.Fprint(w, "instance-role")
+		case "/latest/meta-data/iam/security-credentials/instance-role":
+			fmt.Fprintf(w, `{"Code": "Success", "AccessKeyId": "ASIAINSTANCE", "SecretAccessKey": "secret",`+
+				` "Token": "instance-token", "Expiration": "%s"}`, expiration)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	t.Setenv("AWS_EC2_METADATA_SERVICE_ENDPOINT", server.URL)
+	for _, name := range []string{
+		"AWS_EC2_METADATA_DISABLED",
+		"AWS_CONTAINER_CREDENTIALS_RELATIVE_URI",
+		"AWS_CONTAINER_CREDENTIALS_FULL_URI",
+		"AWS_ACCESS_KEY_ID",
+		"AWS_ACCESS_KEY",
+		"AWS_SECRET_ACCESS_KEY",
+		"AWS_SECRET_KEY",
+		"AWS_SESSION_TOKEN",
+	} {
+		t.Setenv(name, "")
+	}
+}
+
+func TestAmbientCredsFromMetadata(t *testing.T) {
+	var requests int32
+	fakeMetadata(t, &requests)
+
+	provider := DefaultAmbientProvider()
+	if count := atomic.LoadInt32(&requests); count != 0 {
+		t.Errorf("provider contacted the metadata service before use: %d requests", count)
+	}
+
+	c, err := ambientCreds(TraverseOptions{Ambient: provider})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.AccessKey != "ASIAINSTANCE" || c.SessionToken != "instance-token" {
+		t.Errorf("unexpected credentials: %+v", c)
+	}
+	if c.Expiration.IsZero() || c.ExpiresWithin(30*time.Minute) {
+		t.Errorf("unexpected expiration: %s", c.Expiration)
+	}
+}
+
+func TestAmbientCredsPreferEnvironment(t *testing.T) {
+	var requests int32
+	fakeMetadata(t, &requests)
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIAENV")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+
+	provider := DefaultAmbientProvider()
+	// The environment is captured when the provider is created
+	os.Unsetenv("AWS_ACCESS_KEY_ID")
+	os.Unsetenv("AWS_SECRET_ACCESS_KEY")
+
+	c, err := ambientCreds(TraverseOptions{Ambient: provider})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.AccessKey != "AKIAENV" || !c.Expiration.IsZero() {
+		t.Errorf("unexpected credentials: %+v", c)
+	}
+	if count := atomic.LoadInt32(&requests); count != 0 {
+		t.Errorf("metadata service was contacted despite environment credentials: %d requests", count)
+	}
+}
@@ -1,12 +1,25 @@
 package travel
 
 import (
-	"fmt"
+	"context"
+	"encoding/json"
+	"os"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/akerl/voyager/v3/endpoint"
+	"github.com/akerl/voyager/v3/internal/dirs"
+	"github.com/akerl/voyager/v3/internal/lockedfile"
 
 	"github.com/akerl/speculate/v2/creds"
 	"github.com/aws/aws-sdk-go/service/sts"
 )
 
+const (
+	cacheFileName = "credential_cache"
+)
+
 // Cache defines a credential caching object
 type Cache interface {
 	Get(Hop) (creds.Creds, bool)
@@ -14,23 +27,84 @@ type Cache interface {
 	Delete(Hop) error
 }
 
+// ExpiringCache is a Cache which also stores when credentials expire
+// Credentials from caches which don't implement it have no known expiration,
+// and are only checked by calling GetCallerIdentity
+type ExpiringCache interface {
+	Cache
+	GetExpiring(Hop) (Creds, bool)
+	PutExpiring(Hop, Creds) error
+}
+
+func cacheGet(c Cache, h Hop) (Creds, bool) {
+	if ec, ok := c.(ExpiringCache); ok {
+		return ec.GetExpiring(h)
+	}
+	cachedCreds, ok := c.Get(h)
+	return Creds{Creds: cachedCreds}, ok
+}
+
+func cachePut(c Cache, h Hop, newCreds Creds) error {
+	if ec, ok := c.(ExpiringCache); ok {
+		return ec.PutExpiring(h, newCreds)
+	}
+	return c.Put(h, newCreds.Creds)
+}
+
 // CheckCache returns credentials if they exist in the cache and are still valid
-// If the credentials exist but are invalid/expired, it removes them from the cache
+// If the credentials exist but are invalid, expired, or within the RefreshWindow
+// of expiring, it removes them from the cache
+// Validation uses the hop's endpoint settings from the default endpoint config
 func CheckCache(c Cache, h Hop) (creds.Creds, bool) {
+	cachedCreds, ok := CheckCacheWithEndpoints(c, h, nil)
+	return cachedCreds.Creds, ok
+}
+
+// CheckCacheWithEndpoints is CheckCache using the given endpoint config, or the default config
+// if nil, and returns the credentials' expiration
+func CheckCacheWithEndpoints(c Cache, h Hop, endpoints *endpoint.Config) (Creds, bool) {
+	return CheckCacheWithContext(context.Background(), c, h, endpoints)
+}
+
+// CheckCacheWithContext is CheckCacheWithEndpoints with a context for the validation call
+func CheckCacheWithContext(ctx context.Context, c Cache, h Hop, endpoints *endpoint.Config) (Creds, bool) {
 	logger.DebugMsgf("checking cache for %+v", h)
-	cachedCreds, ok := c.Get(h)
+	cachedCreds, ok := cacheGet(c, h)
 	if !ok {
-		return creds.Creds{}, false
+		return Creds{}, false
+	}
+	if cachedCreds.ExpiresWithin(RefreshWindow) {
+		logger.DebugMsgf("cached creds expired at %s", cachedCreds.Expiration)
+		c.Delete(h)
+		return Creds{}, false
 	}
-	client, err := cachedCreds.Client()
+	settings, err := TraverseOptions{Endpoints: endpoints}.endpointSettings(h.Account.Account, cachedCreds.Region)
+	if err != nil {
+		logger.InfoMsgf("failed to load endpoint settings: %s", err)
+		return Creds{}, false
+	}
+	client, err := settings.STSClient(cachedCreds.Creds)
 	if err == nil {
-		_, err := client.GetCallerIdentity(&sts.GetCallerIdentityInput{})
+		_, err := client.GetCallerIdentityWithContext(ctx, &sts.GetCallerIdentityInput{})
 		if err == nil {
 			return cachedCreds, true
 		}
 	}
 	c.Delete(h)
-	return creds.Creds{}, false
+	return Creds{}, false
+}
+
+// CacheKey returns the key under which caches store the hop's credentials
+func (h Hop) CacheKey() string {
+	return h.toKey()
+}
+
+// CacheProfile returns the profile whose credentials the hop's cached credentials derive from
+func (h Hop) CacheProfile() string {
+	if h.Profile != "" {
+		return h.Profile
+	}
+	return h.origin
 }
 
 // NullCache implements an empty cache which stores nothing
@@ -52,40 +126,194 @@ func (nc *NullCache) Delete(_ Hop) error {
 }
 
 // MapCache stores credentials in a map object based on the hop information
+// It is safe for concurrent use
 type MapCache struct {
-	creds map[string]creds.Creds
+	creds map[string]Creds
+	lock  sync.Mutex
 }
 
-// Put stores the credentials in the map
+// Put stores the credentials in the map, with no expiration
 func (mc *MapCache) Put(h Hop, c creds.Creds) error {
-	key := mc.hopToKey(h)
+	return mc.PutExpiring(h, Creds{Creds: c})
+}
+
+// Get returns credentials from the map, if they exist
+func (mc *MapCache) Get(h Hop) (creds.Creds, bool) {
+	c, ok := mc.GetExpiring(h)
+	return c.Creds, ok
+}
+
+// PutExpiring stores the credentials and their expiration in the map
+func (mc *MapCache) PutExpiring(h Hop, c Creds) error {
+	key := h.toKey()
 	logger.DebugMsgf("mapcache: caching %s", key)
+	mc.lock.Lock()
+	defer mc.lock.Unlock()
 	if mc.creds == nil {
-		mc.creds = map[string]creds.Creds{}
+		mc.creds = map[string]Creds{}
 	}
 	mc.creds[key] = c
 	return nil
 }
 
-// Get returns credentials from the map, if they exist
-func (mc *MapCache) Get(h Hop) (creds.Creds, bool) {
-	key := mc.hopToKey(h)
+// GetExpiring returns credentials and their expiration from the map, if they exist
+func (mc *MapCache) GetExpiring(h Hop) (Creds, bool) {
+	key := h.toKey()
 	logger.DebugMsgf("mapcache: getting %s", key)
+	mc.lock.Lock()
+	defer mc.lock.Unlock()
 	creds, ok := mc.creds[key]
 	return creds, ok
 }
 
 // Delete removes credentials from the cache
 func (mc *MapCache) Delete(h Hop) error {
-	key := mc.hopToKey(h)
+	key := h.toKey()
 	logger.DebugMsgf("mapcache: deleting %s", key)
+	mc.lock.Lock()
+	defer mc.lock.Unlock()
 	delete(mc.creds, key)
 	return nil
 }
 
-func (mc *MapCache) hopToKey(h Hop) string {
-	if h.Profile != "" {
-		return fmt.Sprintf("profile--%s", h.Profile)
+// FileCache stores credentials on disk, so they can be reused across invocations
+// Updates hold an exclusive lock on a sidecar lock file and replace the cache file
+// atomically, so concurrent voyager processes do not lose each other's entries
+type FileCache struct {
+	Path string
+}
+
+type fileCacheEntry struct {
+	AccessKey    string
+	SecretKey    string
+	SessionToken string
+	Region       string
+	Expiration   time.Time
+}
+
+// Put stores the credentials in the cache file, with no expiration
+func (fc *FileCache) Put(h Hop, c creds.Creds) error {
+	return fc.PutExpiring(h, Creds{Creds: c})
+}
+
+// Get returns credentials from the cache file, if they exist
+func (fc *FileCache) Get(h Hop) (creds.Creds, bool) {
+	c, ok := fc.GetExpiring(h)
+	return c.Creds, ok
+}
+
+// PutExpiring stores the credentials and their expiration in the cache file
+func (fc *FileCache) PutExpiring(h Hop, c Creds) error {
+	key := h.toKey()
+	logger.DebugMsgf("filecache: caching %s", key)
+	return fc.update(func(entries map[string]fileCacheEntry) bool {
+		entries[key] = fileCacheEntry{
+			AccessKey:    c.AccessKey,
+			SecretKey:    c.SecretKey,
+			SessionToken: c.SessionToken,
+			Region:       c.Region,
+			Expiration:   c.Expiration,
+		}
+		return true
+	})
+}
+
+// GetExpiring returns credentials and their expiration from the cache file, if they exist
+func (fc *FileCache) GetExpiring(h Hop) (Creds, bool) {
+	key := h.toKey()
+	logger.DebugMsgf("filecache: getting %s", key)
+	entries, err := fc.load()
+	if err != nil {
+		logger.InfoMsgf("failed to load cache file: %s", err)
+		return Creds{}, false
+	}
+	entry, ok := entries[key]
+	if !ok {
+		return Creds{}, false
+	}
+	return Creds{
+		Creds: creds.Creds{
+			AccessKey:    entry.AccessKey,
+			SecretKey:    entry.SecretKey,
+			SessionToken: entry.SessionToken,
+			Region:       entry.Region,
+		},
+		Expiration: entry.Expiration,
+	}, true
+}
+
+// Delete removes credentials from the cache file
+func (fc *FileCache) Delete(h Hop) error {
+	key := h.toKey()
+	logger.DebugMsgf("filecache: deleting %s", key)
+	return fc.update(func(entries map[string]fileCacheEntry) bool {
+		if _, ok := entries[key]; !ok {
+			return false
+		}
+		delete(entries, key)
+		return true
+	})
+}
+
+// update loads the cache file under an exclusive lock and saves it if modify reports a change
+func (fc *FileCache) update(modify func(map[string]fileCacheEntry) bool) error {
+	filePath, err := fc.getPath()
+	if err != nil {
+		return err
+	}
+	return lockedfile.WithLock(filePath, func() error {
+		entries, err := fc.load()
+		if err != nil {
+			return err
+		}
+		if !modify(entries) {
+			return nil
+		}
+		return fc.save(entries)
+	})
+}
+
+func (fc *FileCache) getPath() (string, error) {
+	if fc.Path == "" {
+		dir, err := dirs.Config()
+		if err != nil {
+			return "", err
+		}
+		fc.Path = path.Join(dir, cacheFileName)
+		logger.InfoMsgf("set file cache path to default: %s", fc.Path)
+	}
+	return fc.Path, nil
+}
+
+func (fc *FileCache) load() (map[string]fileCacheEntry, error) {
+	entries := map[string]fileCacheEntry{}
+	filePath, err := fc.getPath()
+	if err != nil {
+		return entries, err
+	}
+	data, err := os.ReadFile(filePath)
+	if os.IsNotExist(err) {
+		return entries, nil
+	} else if err != nil {
+		return entries, err
+	}
+	err = json.Unmarshal(data, &entries)
+	return entries, err
+}
+
+func (fc *FileCache) save(entries map[string]fileCacheEntry) error {
+	filePath, err := fc.getPath()
+	if err != nil {
+		return err
+	}
+	for key, entry := range entries {
+		if !entry.Expiration.IsZero() && time.Now().After(entry.Expiration) {
+			delete(entries, key)
+		}
+	}
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return err
 	}
-	return fmt.Sprintf("%s-%s-%t", h.Account.Account, h.Role, h.Mfa)
+	return lockedfile.WriteFile(filePath, data, 0600)
 }
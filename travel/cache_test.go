@@ -0,0 +1,107 @@
+package travel
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/akerl/voyager/v3/cartogram"
+
+	"github.com/akerl/speculate/v2/creds"
+)
+
+func testHop(origin, account string) Hop {
+	return Hop{
+		Account: cartogram.Account{Account: account},
+		Role:    "admin",
+		origin:  origin,
+	}
+}
+
+func TestFileCacheConcurrentPuts(t *testing.T) {
+	cachePath := filepath.Join(t.TempDir(), "cache")
+	expiration := time.Now().Add(time.Hour).Round(0)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			fc := &FileCache{Path: cachePath}
+			c := Creds{Creds: creds.Creds{AccessKey: fmt.Sprintf("key-%d", i)}, Expiration: expiration}
+			if err := fc.PutExpiring(testHop("profile", fmt.Sprintf("%012d", i)), c); err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	fc := &FileCache{Path: cachePath}
+	for i := 0; i < 20; i++ {
+		c, ok := fc.GetExpiring(testHop("profile", fmt.Sprintf("%012d", i)))
+		if !ok {
+			t.Fatalf("missing cache entry %d", i)
+		}
+		if c.AccessKey != fmt.Sprintf("key-%d", i) || !c.Expiration.Equal(expiration) {
+			t.Errorf("unexpected cache entry %d: %+v", i, c)
+		}
+	}
+
+	if err := fc.Delete(testHop("profile", fmt.Sprintf("%012d", 0))); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := fc.GetExpiring(testHop("profile", fmt.Sprintf("%012d", 0))); ok {
+		t.Error("entry was not deleted")
+	}
+}
+
+func TestCacheKeyIncludesOrigin(t *testing.T) {
+	mc := &MapCache{}
+	if err := mc.PutExpiring(testHop("alice", "123456789012"), Creds{Creds: creds.Creds{AccessKey: "alice"}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := mc.GetExpiring(testHop("bob", "123456789012")); ok {
+		t.Error("credentials cached for one origin were returned for another")
+	}
+	if c, ok := mc.GetExpiring(testHop("alice", "123456789012")); !ok || c.AccessKey != "alice" {
+		t.Errorf("expected cached credentials for origin, got %+v, %t", c, ok)
+	}
+}
+
+// plainCache implements only the original Cache interface
+type plainCache struct {
+	mc MapCache
+}
+
+func (pc *plainCache) Get(h Hop) (creds.Creds, bool) {
+	return pc.mc.Get(h)
+}
+
+func (pc *plainCache) Put(h Hop, c creds.Creds) error {
+	return pc.mc.Put(h, c)
+}
+
+func (pc *plainCache) Delete(h Hop) error {
+	return pc.mc.Delete(h)
+}
+
+func TestCacheWithoutExpiration(t *testing.T) {
+	var c Cache = &plainCache{}
+	if _, ok := c.(ExpiringCache); ok {
+		t.Fatal("plainCache should not be an ExpiringCache")
+	}
+	h := testHop("alice", "123456789012")
+	err := cachePut(c, h, Creds{Creds: creds.Creds{AccessKey: "alice"}, Expiration: time.Now().Add(time.Hour)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cached, ok := cacheGet(c, h)
+	if !ok || cached.AccessKey != "alice" {
+		t.Fatalf("creds not cached: %+v, %t", cached, ok)
+	}
+	if !cached.Expiration.IsZero() || cached.ExpiresWithin(RefreshWindow) {
+		t.Errorf("creds without an expiration should not be treated as expiring: %+v", cached)
+	}
+}
@@ -1,15 +1,29 @@
 package travel
 
 import (
+	"context"
+
 	"github.com/akerl/voyager/v3/cartogram"
 
 	"github.com/akerl/input/list"
 )
 
 // Grapher defines a graph resolution object for finding paths to accounts
+// Recent lists account IDs, most recently used first, to rank first when prompting
+// When no role or profile is requested, DefaultRoles and PreferredProfiles (keyed by
+// partition ID) are used if the account offers them
 type Grapher struct {
-	Prompt list.Prompt
-	Pack   cartogram.Pack
+	Prompt            list.Prompt
+	Pack              cartogram.Pack
+	Recent            []string
+	DefaultRoles      []RoleDefault
+	PreferredProfiles map[string]string
+}
+
+// RoleDefault selects a role for accounts matching a set of tag filters
+type RoleDefault struct {
+	Match cartogram.TagFilterSet
+	Role  string
 }
 
 // ResolveOptions allow passing structured parameters for graph resolution
@@ -43,6 +57,20 @@ func (g *Grapher) ResolveAll(args, roleNames, profileNames []string) ([]Path, er
 	return paths, nil
 }
 
+// ResolveAllWithContext is ResolveAll, returning early if the context is cancelled
+func (g *Grapher) ResolveAllWithContext(ctx context.Context, args, roleNames, profileNames []string) ([]Path, error) {
+	var paths []Path
+	err := runWithContext(ctx, func() error {
+		var err error
+		paths, err = g.ResolveAll(args, roleNames, profileNames)
+		return err
+	})
+	if err != nil {
+		return []Path{}, err
+	}
+	return paths, nil
+}
+
 // Resolve selects a valid path to the target account and role
 func (g *Grapher) Resolve(args, roleNames, profileNames []string) (Path, error) {
 	opts := ResolveOptions{
@@ -71,18 +99,52 @@ func (g *Grapher) ResolveWithOptions(opts ResolveOptions) (Path, error) {
 	return g.filterPaths(account, opts.RoleNames, opts.ProfileNames)
 }
 
+// ResolveWithContext is ResolveWithOptions, returning early if the context is cancelled
+// This allows callers to stop waiting on interactive prompts, such as on a timeout,
+// but the prompt itself cannot be interrupted and keeps reading input in the background
+func (g *Grapher) ResolveWithContext(ctx context.Context, opts ResolveOptions) (Path, error) {
+	var path Path
+	err := runWithContext(ctx, func() error {
+		var err error
+		path, err = g.ResolveWithOptions(opts)
+		return err
+	})
+	if err != nil {
+		return Path{}, err
+	}
+	return path, nil
+}
+
+// runWithContext runs a blocking function, returning early if the context is cancelled
+// The function keeps running in the background, and its results are discarded
+func runWithContext(ctx context.Context, fn func() error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- fn()
+	}()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case err := <-errCh:
+		return err
+	}
+}
+
 func (g *Grapher) filterPaths(account cartogram.Account, r, p []string) (Path, error) {
 	paths, err := g.findAllPaths(account)
 	if err != nil {
 		return Path{}, err
 	}
 
-	paths, err = g.filterByRole(paths, r)
+	paths, err = g.filterByRole(paths, g.defaultRole(account, paths, r))
 	if err != nil {
 		return Path{}, err
 	}
 
-	paths, err = g.filterByProfile(paths, p)
+	paths, err = g.filterByProfile(paths, g.preferredProfile(account, paths, p))
 	if err != nil {
 		return Path{}, err
 	}
@@ -93,9 +155,50 @@ func (g *Grapher) filterPaths(account cartogram.Account, r, p []string) (Path, e
 	return paths[0], nil
 }
 
+func (g *Grapher) defaultRole(account cartogram.Account, paths []Path, roleNames []string) []string {
+	if anyNonEmpty(roleNames) {
+		return roleNames
+	}
+	allRoles := uniquePathAttributes(paths, func(p Path) string {
+		return p[len(p)-1].Role
+	})
+	for _, item := range g.DefaultRoles {
+		if item.Match.Match(account) && stringInSlice(allRoles, item.Role) {
+			logger.InfoMsgf("using default role for account %s: %s", account.Account, item.Role)
+			return []string{item.Role}
+		}
+	}
+	return roleNames
+}
+
+func (g *Grapher) preferredProfile(account cartogram.Account, paths []Path, profileNames []string) []string {
+	if anyNonEmpty(profileNames) {
+		return profileNames
+	}
+	region := account.Region
+	if region == "" {
+		region = "us-east-1"
+	}
+	profile, ok := g.PreferredProfiles[partitionForRegion(region)]
+	if !ok {
+		return profileNames
+	}
+	allProfiles := uniquePathAttributes(paths, func(p Path) string {
+		return p[0].Origin()
+	})
+	if stringInSlice(allProfiles, profile) {
+		logger.InfoMsgf("using preferred profile for account %s: %s", account.Account, profile)
+		return []string{profile}
+	}
+	return profileNames
+}
+
 func (g *Grapher) selectTargetAccount(args []string) (cartogram.Account, error) {
 	logger.InfoMsgf("looking up account based on %v", args)
-	return g.Pack.FindWithPrompt(args, g.Prompt)
+	return g.Pack.FindWithOptions(args, cartogram.FindOptions{
+		Prompt: g.Prompt,
+		Recent: g.Recent,
+	})
 }
 
 func (g *Grapher) findAllPaths(account cartogram.Account) ([]Path, error) {
@@ -118,7 +221,19 @@ func (g *Grapher) findPathToRole(account cartogram.Account, role cartogram.Role)
 
 	for _, item := range role.Sources {
 		srcAccount, srcRole := item.Parse()
-		if srcAccount != "" {
+		if item.IsWebIdentity() {
+			if !webIdentityAvailable(item.WebIdentity) {
+				logger.DebugMsgf("skipping web identity source without a token: %s/%s", account.Account, role.Name)
+				continue
+			}
+			allPaths = append(allPaths, Path{{
+				WebIdentity: item.WebIdentity,
+			}})
+		} else if item.IsAmbient() {
+			allPaths = append(allPaths, Path{{
+				Ambient: true,
+			}})
+		} else if srcAccount != "" {
 			newAccount, newRole, ok := g.pathIsViable(srcAccount, srcRole)
 			if !ok {
 				continue
@@ -135,14 +250,19 @@ func (g *Grapher) findPathToRole(account cartogram.Account, role cartogram.Role)
 		}
 	}
 
-	myHop := Hop{
-		Role:    role.Name,
-		Account: account,
-		Mfa:     role.Mfa,
-	}
-
 	for i := range allPaths {
-		allPaths[i] = append(allPaths[i], myHop)
+		// Ambient origins are checked for role sessions when traversed, since that
+		// depends on the credentials found at the time
+		hop := Hop{
+			Role:    role.Name,
+			Account: account,
+			Mfa:     role.Mfa,
+			Chained: len(allPaths[i]) > 1,
+		}
+		if len(allPaths[i]) == 1 {
+			hop.WebIdentity = allPaths[i][0].WebIdentity
+		}
+		allPaths[i] = append(allPaths[i], hop)
 	}
 	return allPaths, nil
 }
@@ -183,7 +303,7 @@ func (g *Grapher) filterByRole(paths []Path, roleNames []string) ([]Path, error)
 
 func (g *Grapher) filterByProfile(paths []Path, profileNames []string) ([]Path, error) {
 	af := func(p Path) string {
-		return p[0].Profile
+		return p[0].Origin()
 	}
 
 	allProfiles := uniquePathAttributes(paths, af)
@@ -1,27 +1,22 @@
 package travel
 
 import (
-	"os"
 	"slices"
-
-	"github.com/akerl/speculate/v2/creds"
 )
 
-func clearEnvironment() error {
-	for varName := range creds.Translations["envvar"] {
-		logger.InfoMsgf("Unsetting env var: %s", varName)
-		err := os.Unsetenv(varName)
-		if err != nil {
-			return err
-		}
-	}
-	return nil
-}
-
 func stringInSlice(list []string, key string) bool {
 	return slices.Contains(list, key)
 }
 
+func anyNonEmpty(list []string) bool {
+	for _, item := range list {
+		if item != "" {
+			return true
+		}
+	}
+	return false
+}
+
 func sliceUnion(a []string, b []string) []string {
 	var res []string
 	for _, item := range a {
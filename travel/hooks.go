@@ -0,0 +1,199 @@
+package travel
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"slices"
+	"time"
+
+	"github.com/akerl/voyager/v3/cartogram"
+	"github.com/akerl/voyager/v3/internal/dirs"
+)
+
+const (
+	hooksFileName = "hooks"
+
+	// HookStagePre runs before a hop, and can veto it
+	HookStagePre = "pre"
+	// HookStagePost runs after a hop, whether it succeeded or not
+	HookStagePost = "post"
+)
+
+// HookEvent describes a hop to a Hook
+type HookEvent struct {
+	Stage      string     `json:"stage"`
+	Command    string     `json:"command,omitempty"`
+	Path       Path       `json:"path"`
+	Hop        Hop        `json:"hop"`
+	Cached     bool       `json:"cached,omitempty"`
+	Expiration *time.Time `json:"expiration,omitempty"`
+	Error      string     `json:"error,omitempty"`
+}
+
+// Hook runs site-specific logic around each hop
+// An error from BeforeHop vetoes the traversal; errors from AfterHop are only logged
+type Hook interface {
+	BeforeHop(HookEvent) error
+	AfterHop(HookEvent) error
+}
+
+// VetoError is returned when a hook refuses a hop
+type VetoError struct {
+	Hop Hop
+	Err error
+}
+
+func (e VetoError) Error() string {
+	return fmt.Sprintf("traversal to %s/%s vetoed by hook: %s", e.Hop.Account.Account, e.Hop.Role, e.Err)
+}
+
+func (e VetoError) Unwrap() error {
+	return e.Err
+}
+
+func (h Hop) runPreHooks(opts TraverseOptions) error {
+	event := HookEvent{Stage: HookStagePre, Command: opts.Command, Path: opts.path, Hop: h}
+	for _, hook := range opts.Hooks {
+		if err := hook.BeforeHop(event); err != nil {
+			return VetoError{Hop: h, Err: err}
+		}
+	}
+	return nil
+}
+
+func (h Hop) runPostHooks(opts TraverseOptions, c Creds, cached bool, hopErr error) {
+	event := HookEvent{
+		Stage:   HookStagePost,
+		Command: opts.Command,
+		Path:    opts.path,
+		Hop:     h,
+		Cached:  cached,
+	}
+	if !c.Expiration.IsZero() {
+		event.Expiration = &c.Expiration
+	}
+	if hopErr != nil {
+		event.Error = hopErr.Error()
+	}
+	for _, hook := range opts.Hooks {
+		if err := hook.AfterHop(event); err != nil {
+			logger.InfoMsgf("post hook failed: %s", err)
+			fmt.Fprintf(os.Stderr, "warning: post-traversal hook failed: %s\n", err)
+		}
+	}
+}
+
+// ExecHook runs an external command for role hops matching its roles and tag filters
+// The HookEvent is passed as JSON on stdin, and a non-zero exit vetoes a pre hook
+// Stages defaults to pre only, and empty Roles or Match lists match every hop
+// An invalid Match filter fails the hook, so a pre hook vetoes rather than being skipped
+type ExecHook struct {
+	Command []string `json:"command"`
+	Stages  []string `json:"stages,omitempty"`
+	Roles   []string `json:"roles,omitempty"`
+	Match   []string `json:"match,omitempty"`
+
+	filter cartogram.TagFilterSet
+}
+
+// LoadHooks reads ExecHooks from a file, using the default path if none is given
+// A missing file results in no hooks
+func LoadHooks(filePath string) ([]Hook, error) {
+	if filePath == "" {
+		dir, err := dirs.Config()
+		if err != nil {
+			return nil, err
+		}
+		filePath = path.Join(dir, hooksFileName)
+	}
+	logger.InfoMsgf("loading hooks from %s", filePath)
+	data, err := os.ReadFile(filePath)
+	if os.IsNotExist(err) {
+		return []Hook{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var execHooks []ExecHook
+	if err := json.Unmarshal(data, &execHooks); err != nil {
+		return nil, err
+	}
+	hooks := make([]Hook, len(execHooks))
+	for index, item := range execHooks {
+		if len(item.Command) == 0 {
+			return nil, fmt.Errorf("hook %d in %s has no command", index, filePath)
+		}
+		if err := item.loadFilter(); err != nil {
+			return nil, fmt.Errorf("hook %d in %s has an invalid match filter: %w", index, filePath, err)
+		}
+		hooks[index] = item
+	}
+	return hooks, nil
+}
+
+// BeforeHop runs the command if the hook is configured for the pre stage
+func (eh ExecHook) BeforeHop(event HookEvent) error {
+	return eh.run(event)
+}
+
+// AfterHop runs the command if the hook is configured for the post stage
+func (eh ExecHook) AfterHop(event HookEvent) error {
+	return eh.run(event)
+}
+
+func (eh ExecHook) run(event HookEvent) error {
+	ok, err := eh.matches(event)
+	if err != nil {
+		return err
+	} else if !ok {
+		return nil
+	}
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	logger.InfoMsgf("running %s hook: %v", event.Stage, eh.Command)
+	cmd := exec.Command(eh.Command[0], eh.Command[1:]...)
+	cmd.Stdin = bytes.NewReader(data)
+	// stdout is reserved for voyager's own output, such as exported credentials
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	cmd.Env = append(os.Environ(), "VOYAGER_HOOK_STAGE="+event.Stage)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s: %w", eh.Command[0], err)
+	}
+	return nil
+}
+
+// loadFilter parses the Match filters, so they are not re-parsed for each event
+func (eh *ExecHook) loadFilter() error {
+	tfs := cartogram.TagFilterSet{}
+	if err := tfs.LoadFromArgs(eh.Match); err != nil {
+		return err
+	}
+	eh.filter = tfs
+	return nil
+}
+
+func (eh ExecHook) matches(event HookEvent) (bool, error) {
+	stages := eh.Stages
+	if len(stages) == 0 {
+		stages = []string{HookStagePre}
+	}
+	if !slices.Contains(stages, event.Stage) {
+		return false, nil
+	}
+	if len(eh.Roles) != 0 && !slices.Contains(eh.Roles, event.Hop.Role) {
+		return false, nil
+	}
+	// Hooks built without LoadHooks have not parsed their filters yet
+	if eh.filter == nil && len(eh.Match) != 0 {
+		if err := eh.loadFilter(); err != nil {
+			return false, fmt.Errorf("invalid hook match filter: %w", err)
+		}
+	}
+	return eh.filter.Match(event.Hop.Account), nil
+}
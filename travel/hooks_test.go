@@ -0,0 +1,70 @@
+package travel
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/akerl/voyager/v3/cartogram"
+)
+
+func hookTestEvent(stage string) HookEvent {
+	return HookEvent{
+		Stage: stage,
+		Hop: Hop{
+			Account: cartogram.Account{Account: "123456789012", Tags: cartogram.Tags{"env": "prod"}},
+			Role:    "admin",
+		},
+	}
+}
+
+func TestLoadHooksRejectsInvalidFilter(t *testing.T) {
+	hooksPath := filepath.Join(t.TempDir(), "hooks")
+	data := `[{"command": ["true"], "match": ["env:("]}]`
+	if err := os.WriteFile(hooksPath, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadHooks(hooksPath); err == nil {
+		t.Error("expected error for invalid match filter")
+	}
+}
+
+func TestLoadHooksParsesFilter(t *testing.T) {
+	hooksPath := filepath.Join(t.TempDir(), "hooks")
+	data := `[{"command": ["false"], "match": ["env:prod"]}, {"command": ["false"], "match": ["env:dev"]}]`
+	if err := os.WriteFile(hooksPath, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+	hooks, err := LoadHooks(hooksPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := hooks[0].BeforeHop(hookTestEvent(HookStagePre)); err == nil {
+		t.Error("expected matching hook to veto")
+	}
+	if err := hooks[1].BeforeHop(hookTestEvent(HookStagePre)); err != nil {
+		t.Errorf("non-matching hook ran: %s", err)
+	}
+}
+
+func TestExecHookInvalidFilterFailsClosed(t *testing.T) {
+	hook := ExecHook{Command: []string{"true"}, Match: []string{"env:("}}
+	opts := TraverseOptions{Hooks: []Hook{hook}}
+	err := hookTestEvent(HookStagePre).Hop.runPreHooks(opts)
+	var veto VetoError
+	if !errors.As(err, &veto) {
+		t.Errorf("expected veto for invalid filter, got %v", err)
+	}
+}
+
+func TestExecHookStages(t *testing.T) {
+	hook := ExecHook{Command: []string{"false"}}
+	if err := hook.AfterHop(hookTestEvent(HookStagePost)); err != nil {
+		t.Errorf("hook ran for a stage it is not configured for: %s", err)
+	}
+	hook.Stages = []string{HookStagePost}
+	if err := hook.AfterHop(hookTestEvent(HookStagePost)); err == nil {
+		t.Error("expected post hook to run")
+	}
+}
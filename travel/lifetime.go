@@ -0,0 +1,76 @@
+package travel
+
+import (
+	"fmt"
+
+	"github.com/akerl/speculate/v2/creds"
+)
+
+// ChainedRoleMaxLifetime is the longest session AWS allows for a role assumed
+// using another role's credentials
+const ChainedRoleMaxLifetime int64 = 3600
+
+// LifetimeError indicates a requested lifetime that a hop cannot provide
+type LifetimeError struct {
+	Hop       Hop
+	Requested int64
+	Max       int64
+	Reason    string
+}
+
+func (l LifetimeError) Error() string {
+	return fmt.Sprintf(
+		"requested lifetime %ds exceeds the %ds %s for %s/%s",
+		l.Requested,
+		l.Max,
+		l.Reason,
+		l.Hop.Account.Account,
+		l.Hop.Role,
+	)
+}
+
+// MaxLifetime returns the longest session the hop can provide, and the reason for the limit
+func (h Hop) MaxLifetime() (int64, string) {
+	if h.Chained {
+		return ChainedRoleMaxLifetime, "limit for chained roles"
+	}
+	if ok, role := h.Account.Roles.Lookup(h.Role); ok && role.MaxSessionDuration != 0 {
+		return role.MaxSessionDuration, "configured maximum for the role"
+	}
+	return creds.AssumeRoleLifetimeLimits.Max, "limit for sts:AssumeRole"
+}
+
+// Lifetime returns the session lifetime to request for the hop
+// A zero requested lifetime uses the AssumeRole default. Requests beyond the hop's
+// maximum are clamped, or rejected with a LifetimeError if strict is set
+// revive:disable-next-line:flag-parameter
+func (h Hop) Lifetime(requested int64, strict bool) (int64, error) {
+	limits := creds.AssumeRoleLifetimeLimits
+	if requested == 0 {
+		requested = limits.Default
+	}
+	if requested < limits.Min {
+		return 0, fmt.Errorf("lifetime must be at least %ds: %d", limits.Min, requested)
+	}
+
+	maxLifetime, reason := h.MaxLifetime()
+	if requested <= maxLifetime {
+		return requested, nil
+	}
+	err := LifetimeError{Hop: h, Requested: requested, Max: maxLifetime, Reason: reason}
+	if strict {
+		return 0, err
+	}
+	logger.InfoMsgf("clamping lifetime: %s", err)
+	return maxLifetime, nil
+}
+
+// ValidateLifetime checks that each hop in the path can provide the requested lifetime
+func (p Path) ValidateLifetime(opts TraverseOptions) error {
+	for _, h := range p[1:] {
+		if _, err := h.Lifetime(opts.Lifetime, opts.StrictLifetime); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package travel
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/client"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/sts"
+)
+
+// DefaultMfaSessionLifetime is how long MFA sessions last when no lifetime is set
+const DefaultMfaSessionLifetime = 12 * 60 * 60
+
+// mfaSession trades the origin's static keys for an MFA-authenticated session
+// The session is cached, so later MFA hops chain from it without prompting again
+func (h Hop) mfaSession(ctx context.Context, c Creds, region string, opts TraverseOptions) (Creds, error) {
+	sessionHop := Hop{Profile: h.Profile, viaMfaSession: true}
+	key := sessionHop.toKey()
+	mutex.Lock(key)
+	defer mutex.Unlock(key)
+
+	if cached, ok := CheckCacheWithContext(ctx, opts.Cache, sessionHop, opts.Endpoints); ok {
+		logger.InfoMsgf("reusing cached mfa session for %s", h.Profile)
+		cached.UserAgentItems = c.UserAgentItems
+		return cached, nil
+	}
+
+	c.Region = region
+	settings, err := opts.endpointSettings("", region)
+	if err != nil {
+		return Creds{}, err
+	}
+	stsClient, err := settings.STSClient(
+		c.Creds,
+		request.WithRetryer(aws.NewConfig(), client.NoOpRetryer{}),
+	)
+	if err != nil {
+		return Creds{}, err
+	}
+	id := &identity{ctx: ctx, client: stsClient}
+	serial, code, err := mfaToken(ctx, id, opts)
+	if err != nil {
+		return Creds{}, err
+	}
+
+	lifetime := opts.MfaSessionLifetime
+	if lifetime == 0 {
+		lifetime = DefaultMfaSessionLifetime
+	}
+	logger.InfoMsgf("getting mfa session token for %s", h.Profile)
+	resp, err := stsClient.GetSessionTokenWithContext(ctx, &sts.GetSessionTokenInput{
+		DurationSeconds: &lifetime,
+		SerialNumber:    &serial,
+		TokenCode:       &code,
+	})
+	if err != nil {
+		return Creds{}, err
+	}
+	session := credsFromSts(resp.Credentials, c)
+	err = cachePut(opts.Cache, sessionHop, session)
+	return session, err
+}
+
+// needsMfaCode returns true if the hop must send an MFA code itself
+func (h Hop) needsMfaCode() bool {
+	return h.Mfa && !h.viaMfaSession
+}
@@ -1,14 +1,19 @@
 package travel
 
 import (
+	"context"
 	"fmt"
+	"time"
 
+	"github.com/akerl/voyager/v3/audit"
 	"github.com/akerl/voyager/v3/cartogram"
+	"github.com/akerl/voyager/v3/endpoint"
 	"github.com/akerl/voyager/v3/pkgver"
 	"github.com/akerl/voyager/v3/profiles"
 
 	"github.com/BurntSushi/locker"
 	"github.com/akerl/speculate/v2/creds"
+	"github.com/aws/aws-sdk-go/aws/credentials"
 )
 
 var mutex *locker.Locker
@@ -22,22 +27,78 @@ type Path []Hop
 
 // Hop defines an individual node on the path from initial credentials
 // to the target role
+// A Hop with WebIdentity set is assumed with an OIDC token instead of the previous credentials,
+// and an origin Hop with Ambient set starts from the machine's own credentials
 type Hop struct {
-	Profile string
-	Account cartogram.Account
-	Role    string
-	Mfa     bool
+	Profile     string
+	Account     cartogram.Account
+	Role        string
+	Mfa         bool
+	Chained     bool
+	WebIdentity *cartogram.WebIdentity `json:",omitempty"`
+	Ambient     bool                   `json:",omitempty"`
+
+	origin        string
+	session       string
+	lifetime      int64
+	viaMfaSession bool
+}
+
+// Creds pairs a set of credentials with their expiration
+// A zero Expiration indicates credentials that do not expire, such as static keypairs
+type Creds struct {
+	creds.Creds
+	Expiration time.Time
+}
+
+// RefreshWindow is how long before expiration credentials are considered due for refresh
+const RefreshWindow = 10 * time.Minute
+
+// Expired returns true if the credentials have passed their expiration
+func (c Creds) Expired() bool {
+	return c.ExpiresWithin(0)
+}
+
+// ExpiresWithin returns true if the credentials will expire within the given duration
+func (c Creds) ExpiresWithin(d time.Duration) bool {
+	return !c.Expiration.IsZero() && time.Now().Add(d).After(c.Expiration)
 }
 
 // TraverseOptions defines the parameters for traversing a path
+// SessionName, SessionTags values, and SourceIdentity are templates rendered with a TagContext
+// for each hop, and the tags and source identity are sent on every hop of the path
 type TraverseOptions struct {
-	MfaCode        string
-	MfaPrompt      creds.MfaPrompt
-	Store          profiles.Store
-	Cache          Cache
-	SessionName    string
-	Lifetime       int64
-	UserAgentItems []creds.UserAgentItem
+	MfaCode           string
+	MfaPrompt         creds.MfaPrompt
+	Store             profiles.Store
+	Cache             Cache
+	SessionName       string
+	Lifetime          int64
+	StrictLifetime    bool
+	UserAgentItems    []creds.UserAgentItem
+	Delegate          Traverser
+	SessionTags       map[string]string
+	TransitiveTagKeys []string
+	AccountTagKeys    []string
+	SourceIdentity    string
+	Endpoints         *endpoint.Config
+	Retry             RetryOptions
+	Audit             audit.Sink
+	Command           string
+	Hooks             []Hook
+	Ambient           credentials.Provider
+	// MfaSession uses a cached GetSessionToken session for MFA hops, so the MFA
+	// code is only requested when that session lapses
+	MfaSession         bool
+	MfaSessionLifetime int64
+
+	originProfile string
+	path          Path
+}
+
+// Traverser performs traversal on behalf of the local process, such as a voyager agent
+type Traverser interface {
+	TraverseWithContext(context.Context, Path, TraverseOptions) (Creds, error)
 }
 
 // DefaultTraverseOptions returns a standard set of TraverseOptions
@@ -46,6 +107,7 @@ func DefaultTraverseOptions() TraverseOptions {
 		MfaPrompt: &creds.DefaultMfaPrompt{},
 		Store:     profiles.NewDefaultStore(),
 		Cache:     &MapCache{},
+		Retry:     RetryOptions{Attempts: DefaultRetryAttempts},
 	}
 }
 
@@ -57,36 +119,56 @@ func (p Path) Traverse() (creds.Creds, error) {
 
 // TraverseWithOptions executes a path and returns the final resulting credentials
 func (p Path) TraverseWithOptions(opts TraverseOptions) (creds.Creds, error) {
+	c, err := p.TraverseWithContext(context.Background(), opts)
+	return c.Creds, err
+}
+
+// TraverseWithContext executes a path with the provided options, returning the
+// final credentials along with their expiration
+// Cancelling the context aborts in-flight API calls and retries, along with MFA prompts
+// from a ContextMfaPrompt; other prompts are abandoned but keep reading in the background
+func (p Path) TraverseWithContext(ctx context.Context, opts TraverseOptions) (Creds, error) {
+	if opts.Delegate != nil {
+		logger.InfoMsgf("delegating traversal of path %+v", p)
+		delegate := opts.Delegate
+		opts.Delegate = nil
+		return delegate.TraverseWithContext(ctx, p, opts)
+	}
+
 	logger.InfoMsgf("traversing path %+v with options %+v", p, opts)
 
-	err := clearEnvironment()
+	err := p.ValidateLifetime(opts)
 	if err != nil {
-		return creds.Creds{}, err
+		return Creds{}, err
 	}
 
 	profileHop, stack := p[0], p[1:]
+	opts.originProfile = profileHop.Origin()
+	opts.path = p
 	logger.InfoMsgf("loading origin hop: %+v", profileHop)
-	profileCreds, err := opts.Store.Lookup(profileHop.Profile)
+	c, err := profileHop.originCreds(opts)
 	if err != nil {
-		return creds.Creds{}, err
+		return Creds{}, err
 	}
 
-	uai := []creds.UserAgentItem{{
-		Name:    "voyager",
-		Version: pkgver.Version,
-	}}
-	for _, x := range opts.UserAgentItems {
-		uai = append(uai, x)
+	stack = append(Path{}, stack...)
+	if profileHop.Ambient && len(stack) != 0 && !stack[0].Chained {
+		stack[0].Chained = isRoleSession(ctx, c, stack[0], opts)
 	}
-
-	c := creds.Creds{
-		AccessKey:      profileCreds.AccessKeyID,
-		SecretKey:      profileCreds.SecretAccessKey,
-		UserAgentItems: uai,
+	if opts.MfaSession && profileHop.Profile != "" && len(stack) != 0 && stack[0].Mfa {
+		region := stack[0].Account.Region
+		if region == "" {
+			region = "us-east-1"
+		}
+		c, err = profileHop.mfaSession(ctx, c, region, opts)
+		if err != nil {
+			return Creds{}, err
+		}
+		stack[0].viaMfaSession = true
 	}
 
 	for _, thisHop := range stack {
-		c, err = thisHop.Traverse(c, opts)
+		c, err = thisHop.TraverseWithContext(ctx, c, opts)
 		if err != nil {
 			break
 		}
@@ -94,45 +176,124 @@ func (p Path) TraverseWithOptions(opts TraverseOptions) (creds.Creds, error) {
 	return c, err
 }
 
+// Origin returns the name of the origin for the first hop of a path
+func (h Hop) Origin() string {
+	if h.WebIdentity != nil {
+		return WebIdentityOrigin
+	}
+	if h.Ambient {
+		return AmbientOrigin
+	}
+	return h.Profile
+}
+
+// originCreds returns the credentials that the first role hop starts from
+// Web identity origins have no credentials of their own
+func (h Hop) originCreds(opts TraverseOptions) (Creds, error) {
+	var c Creds
+	switch {
+	case h.WebIdentity != nil:
+	case h.Ambient:
+		var err error
+		c, err = ambientCreds(opts)
+		if err != nil {
+			return Creds{}, err
+		}
+	default:
+		profileCreds, err := opts.Store.Lookup(h.Profile)
+		if err != nil {
+			return Creds{}, err
+		}
+		c.AccessKey = profileCreds.AccessKeyID
+		c.SecretKey = profileCreds.SecretAccessKey
+	}
+
+	c.UserAgentItems = []creds.UserAgentItem{{
+		Name:    "voyager",
+		Version: pkgver.Version,
+	}}
+	c.UserAgentItems = append(c.UserAgentItems, opts.UserAgentItems...)
+	return c, nil
+}
+
 // Traverse executes a Hop, returning the new credentials
 func (h Hop) Traverse(c creds.Creds, opts TraverseOptions) (creds.Creds, error) {
+	newCreds, err := h.TraverseWithContext(context.Background(), Creds{Creds: c}, opts)
+	return newCreds.Creds, err
+}
+
+// TraverseWithContext executes a Hop, returning the new credentials along with their expiration
+func (h Hop) TraverseWithContext(ctx context.Context, c Creds, opts TraverseOptions) (Creds, error) {
+	if err := ctx.Err(); err != nil {
+		return Creds{}, err
+	}
+
+	event := audit.Event{
+		Command:     opts.Command,
+		Profile:     opts.originProfile,
+		Account:     h.Account.Account,
+		Role:        h.Role,
+		Mfa:         h.Mfa,
+		SessionName: opts.SessionName,
+		Cache:       audit.CacheMiss,
+		Result:      audit.ResultSuccess,
+	}
+
+	if err := h.runPreHooks(opts); err != nil {
+		h.finish(opts, event, Creds{}, err)
+		return Creds{}, err
+	}
+
+	h.origin = opts.originProfile
+	h.session = opts.sessionKey()
+	// Invalid lifetimes are rejected by ValidateLifetime before traversal starts
+	h.lifetime, _ = h.Lifetime(opts.Lifetime, opts.StrictLifetime)
 	key := h.toKey()
 	mutex.Lock(key)
 	defer mutex.Unlock(key)
 
-	if cached, ok := CheckCache(opts.Cache, h); ok {
+	if cached, ok := CheckCacheWithContext(ctx, opts.Cache, h, opts.Endpoints); ok {
+		event.Cache = audit.CacheHit
+		h.finish(opts, event, cached, nil)
 		return cached, nil
 	}
 	logger.InfoMsgf("Executing hop: %+v", h)
-	a := creds.AssumeRoleOptions{
-		RoleName:    h.Role,
-		AccountID:   h.Account.Account,
-		SessionName: opts.SessionName,
-		Lifetime:    opts.Lifetime,
-	}
-
-	if h.Mfa {
-		a.UseMfa = true
-		a.MfaCode = opts.MfaCode
-		a.MfaPrompt = opts.MfaPrompt
-	}
 
 	c.Region = h.Account.Region
 	if c.Region == "" {
 		logger.InfoMsg("missing region for hop; inferring us-east-1")
 		c.Region = "us-east-1"
 	}
-	newCreds, err := c.AssumeRole(a)
+	newCreds, err := h.assumeRoleWithRetry(ctx, c, opts, &event)
+	h.finish(opts, event, newCreds, err)
 	if err != nil {
-		return creds.Creds{}, err
+		return Creds{}, err
 	}
-	err = opts.Cache.Put(h, newCreds)
+	logger.InfoMsgf("hop credentials expire at %s", newCreds.Expiration)
+	err = cachePut(opts.Cache, h, newCreds)
 	return newCreds, err
 }
 
+// finish records the outcome of a hop to the audit sink and post hooks
+func (h Hop) finish(opts TraverseOptions, event audit.Event, c Creds, err error) {
+	if err != nil {
+		event.Result = audit.ResultFailure
+		event.Error = err.Error()
+	}
+	audit.Record(opts.Audit, event)
+	h.runPostHooks(opts, c, event.Cache == audit.CacheHit, err)
+}
+
 func (h *Hop) toKey() string {
+	if h.Profile != "" && h.viaMfaSession {
+		return fmt.Sprintf("mfa-session--%s", h.Profile)
+	}
 	if h.Profile != "" {
 		return fmt.Sprintf("profile--%s", h.Profile)
 	}
-	return fmt.Sprintf("%s-%s-%t", h.Account.Account, h.Role, h.Mfa)
+	key := fmt.Sprintf("%s--%s-%s-%t-%d", h.origin, h.Account.Account, h.Role, h.Mfa, h.lifetime)
+	if h.session != "" {
+		key = key + "-" + h.session
+	}
+	return key
 }
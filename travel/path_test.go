@@ -0,0 +1,83 @@
+package travel
+
+import (
+	"context"
+	"os"
+	"slices"
+	"testing"
+
+	"github.com/akerl/voyager/v3/cartogram"
+
+	"github.com/aws/aws-sdk-go/aws/credentials"
+)
+
+type staticStore struct{}
+
+func (s staticStore) Lookup(_ string) (credentials.Value, error) {
+	return credentials.Value{AccessKeyID: "AKIASTORE", SecretAccessKey: "secret"}, nil
+}
+
+func (s staticStore) Check(_ string) bool {
+	return true
+}
+
+func (s staticStore) Delete(_ string) error {
+	return nil
+}
+
+func TestTraverseLeavesEnvironment(t *testing.T) {
+	fake := newFakeSTS(t)
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIAPARENT")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "parent-secret")
+	t.Setenv("AWS_SESSION_TOKEN", "parent-token")
+	t.Setenv("AWS_REGION", "eu-west-1")
+	before := os.Environ()
+
+	path := Path{
+		{Profile: "test"},
+		{Account: cartogram.Account{Account: "123456789012", Region: "us-east-1"}, Role: "admin"},
+		{Account: cartogram.Account{Account: "210987654321", Region: "us-east-1"}, Role: "admin", Chained: true},
+	}
+	opts := TraverseOptions{
+		Store:     staticStore{},
+		Cache:     &MapCache{},
+		Endpoints: fake.endpoints(),
+	}
+	c, err := path.TraverseWithContext(context.Background(), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.AccessKey != "ASIAtester" {
+		t.Errorf("unexpected credentials: %+v", c)
+	}
+
+	after := os.Environ()
+	slices.Sort(before)
+	slices.Sort(after)
+	if !slices.Equal(before, after) {
+		t.Error("traversal changed the process environment")
+	}
+}
+
+func TestTraverseCachesByLifetime(t *testing.T) {
+	fake := newFakeSTS(t)
+	path := Path{
+		{Profile: "test"},
+		{Account: cartogram.Account{Account: "123456789012", Region: "us-east-1"}, Role: "admin"},
+	}
+	opts := TraverseOptions{
+		Store:     staticStore{},
+		Cache:     &MapCache{},
+		Endpoints: fake.endpoints(),
+	}
+
+	for _, lifetime := range []int64{3600, 3600, 43200} {
+		opts.Lifetime = lifetime
+		if _, err := path.TraverseWithContext(context.Background(), opts); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if calls := fake.calls("AssumeRole"); calls != 2 {
+		t.Errorf("expected one AssumeRole per lifetime, got %d", calls)
+	}
+}
@@ -0,0 +1,36 @@
+package travel
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/akerl/input/list"
+)
+
+// AmbiguousError indicates that resolution required a choice that could not be prompted for
+type AmbiguousError struct {
+	Message string
+	Options list.OptionSet
+}
+
+func (a AmbiguousError) Error() string {
+	names := make([]string, len(a.Options))
+	for index, item := range a.Options {
+		names[index] = item.String()
+	}
+	return fmt.Sprintf(
+		"resolution is ambiguous (%s); narrow the selection to one of: %s",
+		strings.TrimSuffix(a.Message, ":"),
+		strings.Join(names, "; "),
+	)
+}
+
+// NonInteractivePrompt is a list.Prompt which refuses to choose between options
+// It is used when no user is available to answer prompts
+type NonInteractivePrompt struct{}
+
+// Execute returns an AmbiguousError describing the available options
+func (n NonInteractivePrompt) Execute(msg string, optSet list.OptionSet) (int, error) {
+	logger.InfoMsgf("refusing to prompt for %s", msg)
+	return -1, AmbiguousError{Message: msg, Options: optSet}
+}
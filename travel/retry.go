@@ -0,0 +1,90 @@
+package travel
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"time"
+
+	"github.com/akerl/voyager/v3/audit"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+)
+
+const (
+	// DefaultRetryAttempts is the number of attempts made for each hop by default
+	DefaultRetryAttempts  = 5
+	defaultRetryBaseDelay = 500 * time.Millisecond
+	defaultRetryMaxDelay  = 20 * time.Second
+)
+
+// RetryOptions controls retrying hops that fail with throttling or transient errors
+// Attempts is the total number of tries, so values below 2 disable retries
+// Hops that use MFA are never retried, since their code cannot be reused
+type RetryOptions struct {
+	Attempts  int
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	OnRetry   func(Hop, int, error)
+}
+
+func (r RetryOptions) delay(attempt int) time.Duration {
+	base := r.BaseDelay
+	if base == 0 {
+		base = defaultRetryBaseDelay
+	}
+	maxDelay := r.MaxDelay
+	if maxDelay == 0 {
+		maxDelay = defaultRetryMaxDelay
+	}
+	backoff := base << (attempt - 1)
+	if backoff > maxDelay || backoff <= 0 {
+		backoff = maxDelay
+	}
+	// Full jitter spreads out workers that were throttled at the same time
+	return time.Duration(rand.Int63n(int64(backoff)) + 1)
+}
+
+func isRetryable(err error) bool {
+	var awsErr awserr.Error
+	if errors.As(err, &awsErr) {
+		err = awsErr
+	}
+	return request.IsErrorThrottle(err) || request.IsErrorRetryable(err)
+}
+
+func (h Hop) assumeRoleWithRetry(
+	ctx context.Context,
+	c Creds,
+	opts TraverseOptions,
+	event *audit.Event,
+) (Creds, error) {
+	prepare := h.assumeRoleCall
+	if h.WebIdentity != nil {
+		prepare = h.webIdentityCall
+	}
+	call, err := prepare(ctx, c, opts, event)
+	if err != nil {
+		return Creds{}, err
+	}
+	attempt := 1
+	for {
+		newCreds, err := call()
+		// MFA codes are single use, so a request that carries one is never resent
+		if err == nil || h.needsMfaCode() || attempt >= opts.Retry.Attempts || !isRetryable(err) {
+			return newCreds, err
+		}
+		wait := opts.Retry.delay(attempt)
+		logger.InfoMsgf("retrying hop after %s (attempt %d): %s", wait, attempt, err)
+		if opts.Retry.OnRetry != nil {
+			opts.Retry.OnRetry(h, attempt, err)
+		}
+		select {
+		case <-ctx.Done():
+			return Creds{}, ctx.Err()
+		case <-time.After(wait):
+		}
+		attempt++
+	}
+}
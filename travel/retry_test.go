@@ -0,0 +1,83 @@
+package travel
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/akerl/voyager/v3/audit"
+	"github.com/akerl/voyager/v3/cartogram"
+
+	"github.com/akerl/speculate/v2/creds"
+)
+
+func retryTestHop() (Hop, Creds) {
+	h := Hop{Account: cartogram.Account{Account: "123456789012"}, Role: "admin"}
+	c := Creds{Creds: creds.Creds{AccessKey: "AKIA", SecretKey: "secret", Region: "us-east-1"}}
+	return h, c
+}
+
+func TestAssumeRoleRetriesOnlyTheCall(t *testing.T) {
+	fake := newFakeSTS(t)
+	fake.Throttle = 2
+	opts := TraverseOptions{
+		Endpoints: fake.endpoints(),
+		Retry:     RetryOptions{Attempts: 5, BaseDelay: time.Millisecond},
+	}
+	h, c := retryTestHop()
+
+	newCreds, err := h.assumeRoleWithRetry(context.Background(), c, opts, &audit.Event{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if newCreds.AccessKey != "ASIAtester" {
+		t.Errorf("unexpected credentials: %+v", newCreds)
+	}
+	if calls := fake.calls("AssumeRole"); calls != 3 {
+		t.Errorf("expected 3 AssumeRole calls, got %d", calls)
+	}
+	if calls := fake.calls("GetCallerIdentity"); calls != 1 {
+		t.Errorf("expected 1 GetCallerIdentity call, got %d", calls)
+	}
+}
+
+func TestAssumeRoleRetryLimit(t *testing.T) {
+	fake := newFakeSTS(t)
+	fake.Throttle = 100
+	opts := TraverseOptions{
+		Endpoints: fake.endpoints(),
+		Retry:     RetryOptions{Attempts: 3, BaseDelay: time.Millisecond},
+	}
+	h, c := retryTestHop()
+
+	retries := 0
+	opts.Retry.OnRetry = func(_ Hop, _ int, _ error) { retries++ }
+	if _, err := h.assumeRoleWithRetry(context.Background(), c, opts, &audit.Event{}); err == nil {
+		t.Fatal("expected throttling error")
+	}
+	if calls := fake.calls("AssumeRole"); calls != 3 {
+		t.Errorf("expected 3 AssumeRole calls without SDK retries, got %d", calls)
+	}
+	if retries != 2 {
+		t.Errorf("expected 2 retries, got %d", retries)
+	}
+}
+
+func TestAssumeRoleMfaNotRetried(t *testing.T) {
+	fake := newFakeSTS(t)
+	fake.Throttle = 100
+	opts := TraverseOptions{
+		Endpoints: fake.endpoints(),
+		MfaCode:   "123456",
+		Retry:     RetryOptions{Attempts: 5, BaseDelay: time.Millisecond},
+	}
+	h, c := retryTestHop()
+	h.Mfa = true
+
+	if _, err := h.assumeRoleWithRetry(context.Background(), c, opts, &audit.Event{}); err == nil {
+		t.Fatal("expected throttling error")
+	}
+	if calls := fake.calls("AssumeRole"); calls != 1 {
+		t.Errorf("expected a single AssumeRole call with an MFA code, got %d", calls)
+	}
+}
@@ -0,0 +1,221 @@
+package travel
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/akerl/voyager/v3/audit"
+	"github.com/akerl/voyager/v3/endpoint"
+
+	"github.com/akerl/speculate/v2/creds"
+	"github.com/aws/aws-sdk-go/aws/client"
+	"github.com/aws/aws-sdk-go/aws/endpoints"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/sts"
+)
+
+func partitionForRegion(region string) string {
+	partition, ok := endpoints.PartitionForRegion(endpoints.DefaultPartitions(), region)
+	if !ok {
+		logger.InfoMsgf("unknown partition for region %s; inferring aws", region)
+		return endpoints.AwsPartitionID
+	}
+	return partition.ID()
+}
+
+func (h Hop) roleArn(region string) string {
+	return fmt.Sprintf(
+		"arn:%s:iam::%s:role/%s",
+		partitionForRegion(region),
+		h.Account.Account,
+		h.Role,
+	)
+}
+
+// stsCall makes a single attempt at the API call which produces a hop's credentials
+type stsCall func() (Creds, error)
+
+// noRetries disables the SDK's own retries for a request, for calls that
+// assumeRoleWithRetry retries itself
+func noRetries(r *request.Request) {
+	r.Retryer = client.NoOpRetryer{}
+}
+
+// assumeRoleCall prepares the AssumeRole call for the hop, noting the session name on the audit event
+// Identity lookups and MFA prompts happen once here, rather than on each attempt of the call
+func (h Hop) assumeRoleCall(ctx context.Context, c Creds, opts TraverseOptions, event *audit.Event) (stsCall, error) {
+	lifetime, err := h.Lifetime(opts.Lifetime, opts.StrictLifetime)
+	if err != nil {
+		return nil, err
+	}
+
+	settings, err := opts.endpointSettings(h.Account.Account, c.Region)
+	if err != nil {
+		return nil, err
+	}
+	stsClient, err := settings.STSClient(c.Creds)
+	if err != nil {
+		return nil, err
+	}
+	id := &identity{ctx: ctx, client: stsClient}
+
+	sessionName, err := h.sessionName(opts)
+	if err != nil {
+		return nil, err
+	}
+	if sessionName == "" {
+		sessionName, err = id.userName()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	event.SessionName = sessionName
+
+	arn := h.roleArn(c.Region)
+	logger.InfoMsgf("generated target arn: %s", arn)
+	params := &sts.AssumeRoleInput{
+		RoleArn:         &arn,
+		RoleSessionName: &sessionName,
+		DurationSeconds: &lifetime,
+	}
+
+	if err := h.applySessionOptions(params, opts); err != nil {
+		return nil, err
+	}
+
+	if h.needsMfaCode() {
+		serial, code, err := mfaToken(ctx, id, opts)
+		if err != nil {
+			return nil, err
+		}
+		params.SerialNumber = &serial
+		params.TokenCode = &code
+	}
+
+	return func() (Creds, error) {
+		logger.InfoMsg("running assumerole api call")
+		resp, err := stsClient.AssumeRoleWithContext(ctx, params, noRetries)
+		if err != nil {
+			return Creds{}, err
+		}
+		return credsFromSts(resp.Credentials, c), nil
+	}, nil
+}
+
+func mfaToken(ctx context.Context, id *identity, opts TraverseOptions) (string, string, error) {
+	serial, err := id.mfaArn()
+	if err != nil {
+		return "", "", err
+	}
+	if opts.MfaCode != "" {
+		logger.InfoMsg("mfa code already provided")
+		return serial, opts.MfaCode, nil
+	}
+	prompt := opts.MfaPrompt
+	if prompt == nil {
+		logger.InfoMsg("using default mfa prompt")
+		prompt = &creds.DefaultMfaPrompt{}
+	}
+	code, err := promptWithContext(ctx, prompt, serial)
+	return serial, code, err
+}
+
+// ContextMfaPrompt is an MfaPrompt which can be abandoned when a context is cancelled
+type ContextMfaPrompt interface {
+	creds.MfaPrompt
+	PromptWithContext(context.Context, string) (string, error)
+}
+
+// promptWithContext stops waiting on an MFA prompt when the context is cancelled
+// Prompts which are not a ContextMfaPrompt cannot be interrupted, so they keep
+// reading input in the background and their result is discarded
+func promptWithContext(ctx context.Context, prompt creds.MfaPrompt, serial string) (string, error) {
+	if cp, ok := prompt.(ContextMfaPrompt); ok {
+		return cp.PromptWithContext(ctx, serial)
+	}
+	type result struct {
+		code string
+		err  error
+	}
+	resultCh := make(chan result, 1)
+	go func() {
+		code, err := prompt.Prompt(serial)
+		resultCh <- result{code: code, err: err}
+	}()
+	select {
+	case <-ctx.Done():
+		return "", ctx.Err()
+	case res := <-resultCh:
+		return res.code, res.err
+	}
+}
+
+func (opts TraverseOptions) endpointSettings(account, region string) (endpoint.Settings, error) {
+	config := opts.Endpoints
+	if config == nil {
+		var err error
+		config, err = endpoint.Default()
+		if err != nil {
+			return endpoint.Settings{}, err
+		}
+	}
+	return config.Lookup(account, region), nil
+}
+
+// identity looks up the caller identity of a client once and reuses the result
+type identity struct {
+	ctx    context.Context
+	client *sts.STS
+	arn    string
+}
+
+func (i *identity) lookup() (string, error) {
+	if i.arn != "" {
+		return i.arn, nil
+	}
+	logger.InfoMsg("looking up identity")
+	resp, err := i.client.GetCallerIdentityWithContext(i.ctx, &sts.GetCallerIdentityInput{})
+	if err != nil {
+		return "", fmt.Errorf(
+			"looking up credential failed. this occurs if your AWS keys are invalid or disabled: %w",
+			err,
+		)
+	}
+	i.arn = *resp.Arn
+	return i.arn, nil
+}
+
+func (i *identity) userName() (string, error) {
+	arn, err := i.lookup()
+	if err != nil {
+		return "", err
+	}
+	chunks := strings.Split(arn, "/")
+	return chunks[len(chunks)-1], nil
+}
+
+func (i *identity) mfaArn() (string, error) {
+	arn, err := i.lookup()
+	if err != nil {
+		return "", err
+	}
+	if !strings.Contains(arn, ":user/") {
+		return "", fmt.Errorf("failed to parse MFA ARN for non-user: %s", arn)
+	}
+	return strings.Replace(arn, ":user/", ":mfa/", 1), nil
+}
+
+func credsFromSts(stsCreds *sts.Credentials, parent Creds) Creds {
+	return Creds{
+		Creds: creds.Creds{
+			AccessKey:      *stsCreds.AccessKeyId,
+			SecretKey:      *stsCreds.SecretAccessKey,
+			SessionToken:   *stsCreds.SessionToken,
+			Region:         parent.Region,
+			UserAgentItems: parent.UserAgentItems,
+		},
+		Expiration: *stsCreds.Expiration,
+	}
+}
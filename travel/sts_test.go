@@ -0,0 +1,82 @@
+package travel
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+
+	"github.com/akerl/voyager/v3/endpoint"
+)
+
+// fakeSTS serves the STS query API, recording each request
+type fakeSTS struct {
+	CallerArn string
+	// Throttle is the number of AssumeRole calls to reject before succeeding
+	Throttle int
+
+	server   *httptest.Server
+	requests []url.Values
+	lock     sync.Mutex
+}
+
+func newFakeSTS(t *testing.T) *fakeSTS {
+	f := &fakeSTS{CallerArn: "arn:aws:iam::123456789012:user/tester"}
+	f.server = httptest.NewServer(http.HandlerFunc(f.handle))
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+func (f *fakeSTS) endpoints() *endpoint.Config {
+	return &endpoint.Config{Global: endpoint.Settings{URL: f.server.URL}}
+}
+
+func (f *fakeSTS) calls(action string) int {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	count := 0
+	for _, item := range f.requests {
+		if item.Get("Action") == action {
+			count++
+		}
+	}
+	return count
+}
+
+func (f *fakeSTS) handle(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	f.lock.Lock()
+	f.requests = append(f.requests, r.PostForm)
+	throttle := false
+	if r.PostForm.Get("Action") == "AssumeRole" && f.Throttle > 0 {
+		f.Throttle--
+		throttle = true
+	}
+	f.lock.Unlock()
+
+	w.Header().Set("Content-Type", "text/xml")
+	switch {
+	case throttle:
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `<ErrorResponse><Error><Type>Sender</Type><Code>Throttling</Code>`+
+			`<Message>Rate exceeded</Message></Error><RequestId>1</RequestId></ErrorResponse>`)
+	case r.PostForm.Get("Action") == "GetCallerIdentity":
+		fmt.Fprintf(w, `<GetCallerIdentityResponse><GetCallerIdentityResult>`+
+			`<Arn>%s</Arn><UserId>AIDAEXAMPLE</UserId><Account>123456789012</Account>`+
+			`</GetCallerIdentityResult></GetCallerIdentityResponse>`, f.CallerArn)
+	case r.PostForm.Get("Action") == "AssumeRole":
+		fmt.Fprintf(w, `<AssumeRoleResponse><AssumeRoleResult><Credentials>`+
+			`<AccessKeyId>ASIA%s</AccessKeyId><SecretAccessKey>secret</SecretAccessKey>`+
+			`<SessionToken>token</SessionToken><Expiration>2099-01-01T00:00:00Z</Expiration>`+
+			`</Credentials></AssumeRoleResult></AssumeRoleResponse>`, r.PostForm.Get("RoleSessionName"))
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `<ErrorResponse><Error><Type>Sender</Type><Code>InvalidAction</Code>`+
+			`<Message>unsupported</Message></Error><RequestId>1</RequestId></ErrorResponse>`)
+	}
+}
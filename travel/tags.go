@@ -0,0 +1,142 @@
+package travel
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os/user"
+	"slices"
+	"sort"
+	"text/template"
+
+	"github.com/akerl/voyager/v3/cartogram"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/sts"
+)
+
+// TagContext is the data available to session tag and source identity templates
+type TagContext struct {
+	User    string
+	Account string
+	Role    string
+	Tags    cartogram.Tags
+}
+
+func (h Hop) tagContext() (TagContext, error) {
+	usr, err := user.Current()
+	if err != nil {
+		return TagContext{}, err
+	}
+	return TagContext{
+		User:    usr.Username,
+		Account: h.Account.Account,
+		Role:    h.Role,
+		Tags:    h.Account.Tags,
+	}, nil
+}
+
+// sessionKey fingerprints the tag and source identity options, so that cached
+// credentials are only reused for hops that requested the same session settings
+func (opts TraverseOptions) sessionKey() string {
+	if len(opts.SessionTags) == 0 && len(opts.AccountTagKeys) == 0 && opts.SourceIdentity == "" {
+		return ""
+	}
+	data, err := json.Marshal([]interface{}{
+		opts.SessionTags, opts.TransitiveTagKeys, opts.AccountTagKeys, opts.SourceIdentity,
+	})
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
+
+// sessionName renders the SessionName template for the hop, returning an empty string if unset
+func (h Hop) sessionName(opts TraverseOptions) (string, error) {
+	if opts.SessionName == "" {
+		return "", nil
+	}
+	tc, err := h.tagContext()
+	if err != nil {
+		return "", err
+	}
+	return renderTemplate(opts.SessionName, tc)
+}
+
+func renderTemplate(text string, tc TagContext) (string, error) {
+	tmpl, err := template.New("").Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return "", err
+	}
+	var buffer bytes.Buffer
+	if err := tmpl.Execute(&buffer, tc); err != nil {
+		return "", err
+	}
+	return buffer.String(), nil
+}
+
+// sessionTags collects the tags to send for the hop
+// Tags from AccountTagKeys are read from the hop's account, and SessionTags values
+// are rendered as templates. Chained hops skip transitive keys, because those
+// are inherited from the previous session and cannot be set again
+func (h Hop) sessionTags(opts TraverseOptions, tc TagContext) (map[string]string, error) {
+	tags := map[string]string{}
+	for _, key := range opts.AccountTagKeys {
+		if value, ok := h.Account.Tags[key]; ok {
+			tags[key] = value
+		}
+	}
+	for key, text := range opts.SessionTags {
+		value, err := renderTemplate(text, tc)
+		if err != nil {
+			return nil, err
+		}
+		tags[key] = value
+	}
+	if h.Chained {
+		for _, key := range opts.TransitiveTagKeys {
+			delete(tags, key)
+		}
+	}
+	return tags, nil
+}
+
+func (h Hop) applySessionOptions(params *sts.AssumeRoleInput, opts TraverseOptions) error {
+	if len(opts.SessionTags) == 0 && len(opts.AccountTagKeys) == 0 && opts.SourceIdentity == "" {
+		return nil
+	}
+
+	tc, err := h.tagContext()
+	if err != nil {
+		return err
+	}
+
+	tags, err := h.sessionTags(opts, tc)
+	if err != nil {
+		return err
+	}
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		params.Tags = append(params.Tags, &sts.Tag{Key: aws.String(key), Value: aws.String(tags[key])})
+		if !h.Chained && slices.Contains(opts.TransitiveTagKeys, key) {
+			params.TransitiveTagKeys = append(params.TransitiveTagKeys, aws.String(key))
+		}
+	}
+	logger.InfoMsgf("sending session tags: %v", keys)
+
+	if opts.SourceIdentity != "" {
+		identity, err := renderTemplate(opts.SourceIdentity, tc)
+		if err != nil {
+			return err
+		}
+		logger.InfoMsgf("setting source identity: %s", identity)
+		params.SourceIdentity = &identity
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package travel
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/akerl/voyager/v3/audit"
+	"github.com/akerl/voyager/v3/cartogram"
+
+	"github.com/aws/aws-sdk-go/service/sts"
+)
+
+const (
+	// WebIdentityOrigin is the origin name used to select web identity paths
+	WebIdentityOrigin = "web_identity"
+
+	webIdentityTokenFileEnvVar = "AWS_WEB_IDENTITY_TOKEN_FILE"
+	defaultWebIdentitySession  = "voyager"
+)
+
+func webIdentityTokenFile(wi *cartogram.WebIdentity) string {
+	if wi.TokenFile != "" {
+		return os.ExpandEnv(wi.TokenFile)
+	}
+	return os.Getenv(webIdentityTokenFileEnvVar)
+}
+
+// webIdentityAvailable checks if a token can be read, so that paths which need one
+// are skipped on machines without it
+func webIdentityAvailable(wi *cartogram.WebIdentity) bool {
+	if len(wi.TokenCommand) != 0 {
+		_, err := exec.LookPath(wi.TokenCommand[0])
+		return err == nil
+	}
+	tokenFile := webIdentityTokenFile(wi)
+	if tokenFile == "" {
+		return false
+	}
+	_, err := os.Stat(tokenFile)
+	return err == nil
+}
+
+func readWebIdentityToken(ctx context.Context, wi *cartogram.WebIdentity) (string, error) {
+	var data []byte
+	var err error
+	if len(wi.TokenCommand) != 0 {
+		logger.InfoMsgf("running web identity token command: %v", wi.TokenCommand)
+		cmd := exec.CommandContext(ctx, wi.TokenCommand[0], wi.TokenCommand[1:]...)
+		var stdout bytes.Buffer
+		cmd.Stdout = &stdout
+		cmd.Stderr = os.Stderr
+		err = cmd.Run()
+		data = stdout.Bytes()
+	} else {
+		tokenFile := webIdentityTokenFile(wi)
+		if tokenFile == "" {
+			return "", fmt.Errorf("no web identity token file configured")
+		}
+		logger.InfoMsgf("reading web identity token from %s", tokenFile)
+		data, err = os.ReadFile(tokenFile)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to load web identity token: %w", err)
+	}
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		return "", fmt.Errorf("web identity token is empty")
+	}
+	return token, nil
+}
+
+// webIdentityCall prepares the first hop of a path that starts from an OIDC token
+func (h Hop) webIdentityCall(
+	ctx context.Context,
+	c Creds,
+	opts TraverseOptions,
+	event *audit.Event,
+) (stsCall, error) {
+	lifetime, err := h.Lifetime(opts.Lifetime, opts.StrictLifetime)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := readWebIdentityToken(ctx, h.WebIdentity)
+	if err != nil {
+		return nil, err
+	}
+
+	sessionName, err := h.sessionName(opts)
+	if err != nil {
+		return nil, err
+	}
+	if sessionName == "" {
+		sessionName = defaultWebIdentitySession
+	}
+	event.SessionName = sessionName
+
+	if len(opts.SessionTags) != 0 || len(opts.AccountTagKeys) != 0 || opts.SourceIdentity != "" {
+		logger.InfoMsg("session tags and source identity are not sent for web identity hops")
+	}
+
+	settings, err := opts.endpointSettings(h.Account.Account, c.Region)
+	if err != nil {
+		return nil, err
+	}
+	stsClient, err := settings.STSClient(c.Creds)
+	if err != nil {
+		return nil, err
+	}
+
+	arn := h.roleArn(c.Region)
+	params := &sts.AssumeRoleWithWebIdentityInput{
+		RoleArn:          &arn,
+		RoleSessionName:  &sessionName,
+		DurationSeconds:  &lifetime,
+		WebIdentityToken: &token,
+	}
+	return func() (Creds, error) {
+		logger.InfoMsgf("running assumerolewithwebidentity for %s", arn)
+		resp, err := stsClient.AssumeRoleWithWebIdentityWithContext(ctx, params, noRetries)
+		if err != nil {
+			return Creds{}, err
+		}
+		return credsFromSts(resp.Credentials, c), nil
+	}, nil
+}
@@ -0,0 +1,142 @@
+package tty
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/akerl/timber/v2/log"
+	"golang.org/x/term"
+)
+
+const (
+	ttyPath            = "/dev/tty"
+	mfaCodeRegexString = `^\d{6}$`
+)
+
+var logger = log.NewLogger("voyager")
+
+var mfaCodeRegex = regexp.MustCompile(mfaCodeRegexString)
+
+// ReadLine prints a message to the terminal and reads a line of input from it
+// If no terminal is available, it falls back to stderr and stdin
+func ReadLine(message string) (string, error) {
+	reader, writer, closer := open()
+	defer closer()
+
+	fmt.Fprint(writer, message)
+	input, err := bufio.NewReader(reader).ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(input), nil
+}
+
+// ReadLineWithContext is ReadLine, abandoning the read if the context is cancelled
+// The read is interrupted with a deadline on the terminal, so no input is consumed
+// after cancellation. Without a terminal it falls back to ReadLine, which cannot be interrupted
+func ReadLineWithContext(ctx context.Context, message string) (string, error) {
+	file, err := os.OpenFile(ttyPath, os.O_RDWR, 0)
+	if err != nil {
+		logger.InfoMsgf("failed to open terminal, using stdin/stderr: %s", err)
+		return ReadLine(message)
+	}
+	defer file.Close()
+
+	fmt.Fprint(file, message)
+	input, err := readLineWithContext(ctx, file)
+	if ctx.Err() != nil {
+		fmt.Fprintln(file)
+	}
+	return input, err
+}
+
+func readLineWithContext(ctx context.Context, file *os.File) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	stop := context.AfterFunc(ctx, func() {
+		if err := file.SetReadDeadline(time.Now()); err != nil {
+			logger.InfoMsgf("failed to interrupt terminal read: %s", err)
+		}
+	})
+	defer stop()
+
+	input, err := bufio.NewReader(file).ReadString('\n')
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return "", ctxErr
+		}
+		return "", err
+	}
+	return strings.TrimSpace(input), nil
+}
+
+// ReadPassword prints a message to the terminal and reads input without echoing it
+func ReadPassword(message string) (string, error) {
+	file, err := os.OpenFile(ttyPath, os.O_RDWR, 0)
+	if err != nil {
+		return "", fmt.Errorf("a terminal is required to read a password: %s", err)
+	}
+	defer file.Close()
+
+	fmt.Fprint(file, message)
+	input, err := term.ReadPassword(int(file.Fd()))
+	fmt.Fprintln(file)
+	if err != nil {
+		return "", err
+	}
+	return string(input), nil
+}
+
+// Println prints a message to the terminal, or to stderr if no terminal is available
+func Println(message string) {
+	_, writer, closer := open()
+	defer closer()
+	fmt.Fprintln(writer, message)
+}
+
+func open() (io.Reader, io.Writer, func()) {
+	file, err := os.OpenFile(ttyPath, os.O_RDWR, 0)
+	if err != nil {
+		logger.InfoMsgf("failed to open terminal, using stdin/stderr: %s", err)
+		return os.Stdin, os.Stderr, func() {}
+	}
+	return file, file, func() { file.Close() }
+}
+
+// MfaPrompt asks for MFA codes on the terminal, keeping stdin and stdout free
+type MfaPrompt struct {
+	PromptTextFunc func(string) string
+}
+
+func defaultPromptTextFunc(_ string) string {
+	return "MFA Code: "
+}
+
+// Prompt asks the user for their MFA token
+func (p *MfaPrompt) Prompt(arn string) (string, error) {
+	return p.PromptWithContext(context.Background(), arn)
+}
+
+// PromptWithContext asks the user for their MFA token, abandoning the prompt if the context is cancelled
+func (p *MfaPrompt) PromptWithContext(ctx context.Context, arn string) (string, error) {
+	logger.InfoMsgf("prompting on terminal for mfa for %s", arn)
+	pf := p.PromptTextFunc
+	if pf == nil {
+		pf = defaultPromptTextFunc
+	}
+	code, err := ReadLineWithContext(ctx, pf(arn))
+	if err != nil {
+		return "", err
+	}
+	if !mfaCodeRegex.MatchString(code) {
+		return "", fmt.Errorf("provided mfa code does not match the necessary format")
+	}
+	return code, nil
+}
@@ -0,0 +1,55 @@
+package tty
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestReadLineWithContext(t *testing.T) {
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+	defer writer.Close()
+
+	if _, err := writer.WriteString(" 123456 \n"); err != nil {
+		t.Fatal(err)
+	}
+	input, err := readLineWithContext(context.Background(), reader)
+	if err != nil || input != "123456" {
+		t.Errorf("unexpected result: %q, %s", input, err)
+	}
+}
+
+func TestReadLineWithContextCancelled(t *testing.T) {
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+	defer writer.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = readLineWithContext(ctx, reader)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline error, got %v", err)
+	}
+
+	// Input typed after cancellation is left for the next reader
+	if _, err := writer.WriteString("later\n"); err != nil {
+		t.Fatal(err)
+	}
+	if err := reader.SetReadDeadline(time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+	buffer := make([]byte, 6)
+	if _, err := io.ReadFull(reader, buffer); err != nil || string(buffer) != "later\n" {
+		t.Errorf("input after cancellation was consumed: %q, %v", buffer, err)
+	}
+}